@@ -0,0 +1,199 @@
+package cocobase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrTokenExpired is returned when a password reset or email
+	// verification token has expired; request a new one
+	ErrTokenExpired = errors.New("cocobase: token expired")
+	// ErrTokenInvalid is returned when a password reset or email
+	// verification token is unknown or was already used
+	ErrTokenInvalid = errors.New("cocobase: token invalid")
+	// ErrInvalidCredentials is returned by ChangePassword when the current
+	// password is wrong
+	ErrInvalidCredentials = errors.New("cocobase: invalid credentials")
+)
+
+// tokenError classifies the server rejecting a one-time token. The result
+// wraps both the sentinel and the *APIError.
+func tokenError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusGone,
+		apiErr.StatusCode < 500 && strings.Contains(strings.ToLower(apiErr.Body), "expired"):
+		return fmt.Errorf("%w: %w", ErrTokenExpired, err)
+	case apiErr.StatusCode == http.StatusBadRequest,
+		apiErr.StatusCode == http.StatusUnauthorized,
+		apiErr.StatusCode == http.StatusNotFound,
+		apiErr.StatusCode == http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", ErrTokenInvalid, err)
+	}
+	return err
+}
+
+// postAuth sends an auth request whose response body is not needed
+func (c *Client) postAuth(ctx context.Context, op Operation, path string, body interface{}, opts []CallOption) error {
+	resp, err := c.request(ctx, op, http.MethodPost, path, body, false, opts...)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// RequestPasswordReset emails a password reset link to email
+func (c *Client) RequestPasswordReset(ctx context.Context, email string, opts ...CallOption) error {
+	body := map[string]string{"email": email}
+	return c.postAuth(ctx, Operation{Name: OpAuthRequestPasswordReset}, "/auth-collections/forgot-password", body, withoutAuth(opts))
+}
+
+// ConfirmPasswordReset sets a new password using the token from the reset
+// email. It fails with ErrTokenExpired or ErrTokenInvalid if the token is
+// rejected. The user is not signed in; call Login with the new password.
+func (c *Client) ConfirmPasswordReset(ctx context.Context, token, newPassword string, opts ...CallOption) error {
+	body := map[string]string{
+		"token":    token,
+		"password": newPassword,
+	}
+	err := c.postAuth(ctx, Operation{Name: OpAuthConfirmPasswordReset}, "/auth-collections/reset-password", body, withoutAuth(opts))
+	return tokenError(err)
+}
+
+// SendVerificationEmail emails a verification link to the signed-in user
+func (c *Client) SendVerificationEmail(ctx context.Context, opts ...CallOption) error {
+	if !c.authenticatedFor(ctx, opts) {
+		return fmt.Errorf("user is not authenticated")
+	}
+	return c.postAuth(ctx, Operation{Name: OpAuthSendVerification}, "/auth-collections/verify-email/send", nil, opts)
+}
+
+// VerifyEmail confirms an email address using the token from the
+// verification email. It fails with ErrTokenExpired or ErrTokenInvalid if
+// the token is rejected. If the client's signed-in user is the one verified,
+// its EmailVerified field is updated.
+func (c *Client) VerifyEmail(ctx context.Context, token string, opts ...CallOption) error {
+	body := map[string]string{"token": token}
+	resp, err := c.request(ctx, Operation{Name: OpAuthVerifyEmail}, http.MethodPost, "/auth-collections/verify-email", body, false, withoutAuth(opts)...)
+	if err != nil {
+		return tokenError(err)
+	}
+	defer resp.Body.Close()
+
+	// The response carries the verified user when the server includes it
+	var verified AppUser
+	if c.decode(resp.Body, &verified) != nil || verified.ID == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	updated := c.user != nil && c.user.ID == verified.ID && !c.user.EmailVerified
+	if updated {
+		user := *c.user
+		user.EmailVerified = true
+		c.user = &user
+	}
+	c.mu.Unlock()
+
+	if updated {
+		c.persistUser(c.currentUser())
+		c.emitAuthEvent(AuthUserUpdated)
+	}
+
+	return nil
+}
+
+// ChangePassword changes the caller's password. The current password is
+// checked by signing in again. When the call runs as the client's signed-in
+// user, the client keeps a session issued for the new password, or its
+// current session if the account uses MFA; other callers' tokens are left
+// as they are. A wrong current password fails with ErrInvalidCredentials.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string, opts ...CallOption) error {
+	session, err := c.changePassword(ctx, currentPassword, newPassword, opts)
+	if err != nil || session == nil || !c.actsAsClientUser(ctx, opts) {
+		return err
+	}
+
+	if err := c.setSession(session); err != nil {
+		return err
+	}
+	c.emitAuthEvent(AuthTokenRefreshed)
+
+	return nil
+}
+
+// changePassword changes the password of the user the call runs as and
+// returns a session issued for the new password, or nil if the account
+// uses MFA
+func (c *Client) changePassword(ctx context.Context, currentPassword, newPassword string, opts []CallOption) (*Session, error) {
+	callOpts := newCallOptions(opts)
+	token := c.resolveToken(ctx, callOpts)
+	if token == "" {
+		return nil, fmt.Errorf("user is not authenticated")
+	}
+
+	var user *AppUser
+	if c.usesClientToken(ctx, callOpts) {
+		user = c.currentUser()
+	}
+	if user == nil {
+		var err error
+		if user, err = c.GetCurrentUser(ctx, opts...); err != nil {
+			return nil, err
+		}
+	}
+
+	credentials := map[string]string{
+		"email":    user.Email,
+		"password": currentPassword,
+	}
+	// With MFA enabled, a challenge means the password was right; the
+	// caller's token, which already passed MFA, makes the change
+	verified, err := c.authenticate(ctx, Operation{Name: OpAuthLogin}, "/auth-collections/login", credentials, opts)
+	switch {
+	case err == nil:
+		token = verified.Token
+	case errors.Is(err, ErrMFARequired):
+	default:
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusBadRequest) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+		}
+		return nil, err
+	}
+
+	body := map[string]string{"password": newPassword}
+	resp, err := c.request(ctx, Operation{Name: OpAuthChangePassword}, http.MethodPatch, "/auth-collections/user", body, false, withToken(opts, token)...)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	c.logger.Info("password changed", "user_id", user.ID)
+
+	// Tokens issued before the change may have been revoked
+	credentials["password"] = newPassword
+	session, err := c.authenticate(ctx, Operation{Name: OpAuthLogin}, "/auth-collections/login", credentials, opts)
+	if errors.Is(err, ErrMFARequired) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("password changed, but signing in again failed: %w", err)
+	}
+
+	return session, nil
+}
+
+func (c *Client) currentUser() *AppUser {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.user
+}
@@ -3,26 +3,33 @@ package cocobase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
-func (c *Client) InitAuth(ctx context.Context) error {
+func (c *Client) InitAuth(ctx context.Context, opts ...CallOption) error {
 	if c.storage == nil {
 		return nil
 	}
 
 	token, err := c.storage.Get("cocobase-token")
 	if err != nil {
+		c.logger.Debug("no stored session to restore")
 		return nil
 	}
 
 	c.mu.Lock()
 	c.token = token
+	c.restoreTokensLocked()
 	c.mu.Unlock()
 
-	user, err := c.GetCurrentUser(ctx)
+	// An expired access token is refreshed here if a refresh token was saved
+	user, err := c.GetCurrentUser(ctx, opts...)
 	if err != nil {
+		c.logger.Warn("failed to restore stored session", "error", err.Error())
 		return err
 	}
 
@@ -30,43 +37,48 @@ func (c *Client) InitAuth(ctx context.Context) error {
 	c.user = user
 	c.mu.Unlock()
 
+	c.logger.Info("session restored", "user_id", user.ID)
+	c.emitAuthEvent(AuthSignedIn)
+
 	return nil
 }
 
-func (c *Client) Login(ctx context.Context, email, password string) error {
+func (c *Client) Login(ctx context.Context, email, password string, opts ...CallOption) error {
 	body := map[string]string{
 		"email":    email,
 		"password": password,
 	}
 	
-	resp, err := c.request(ctx, http.MethodPost, "/auth-collections/login", body, false)
+	session, err := c.authenticate(ctx, Operation{Name: OpAuthLogin}, "/auth-collections/login", body, opts)
 	if err != nil {
+		c.holdMFAChallenge(err)
 		return err
 	}
-	defer resp.Body.Close()
-
-	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
 
-	if err := c.SetToken(tokenResp.AccessToken); err != nil {
+	if err := c.signIn(session); err != nil {
 		return err
 	}
 
-	user, err := c.GetCurrentUser(ctx)
-	if err != nil {
-		return err
+	c.logger.Info("signed in", "user_id", session.User.ID)
+
+	return nil
+}
+
+// holdMFAChallenge keeps a challenge returned by Login for VerifyMFA
+func (c *Client) holdMFAChallenge(err error) {
+	var challenge *MFAChallenge
+	if !errors.As(err, &challenge) {
+		return
 	}
 
 	c.mu.Lock()
-	c.user = user
+	c.mfaChallenge = challenge
 	c.mu.Unlock()
 
-	return nil
+	c.logger.Info("second factor required", "methods", strings.Join(challenge.Methods, ","))
 }
 
-func (c *Client) Register(ctx context.Context, email, password string, data map[string]interface{}) error {
+func (c *Client) Register(ctx context.Context, email, password string, data map[string]interface{}, opts ...CallOption) error {
 	body := map[string]interface{}{
 		"email":    email,
 		"password": password,
@@ -76,82 +88,126 @@ func (c *Client) Register(ctx context.Context, email, password string, data map[
 		body["data"] = data
 	}
 	
-	resp, err := c.request(ctx, http.MethodPost, "/auth-collections/signup", body, false)
+	session, err := c.authenticate(ctx, Operation{Name: OpAuthRegister}, "/auth-collections/signup", body, opts)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	if err := c.signIn(session); err != nil {
+		return err
 	}
 
-	if err := c.SetToken(tokenResp.AccessToken); err != nil {
-		return err
+	c.logger.Info("registered and signed in", "user_id", session.User.ID)
+
+	return nil
+}
+
+// authenticate exchanges credentials for a token and fetches its user
+// without changing the client's state
+func (c *Client) authenticate(ctx context.Context, op Operation, path string, body interface{}, opts []CallOption) (*Session, error) {
+	resp, err := c.request(ctx, op, http.MethodPost, path, body, false, withoutAuth(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var loginResp loginResponse
+	if err := c.decode(resp.Body, &loginResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if loginResp.MFARequired {
+		return nil, &MFAChallenge{Token: loginResp.MFAToken, Methods: loginResp.MFAMethods}
 	}
 
-	user, err := c.GetCurrentUser(ctx)
+	return c.newSessionFromTokens(ctx, loginResp.TokenResponse, opts)
+}
+
+// newSessionFromTokens fetches the user owning tokens and returns their session
+func (c *Client) newSessionFromTokens(ctx context.Context, tokens TokenResponse, opts []CallOption) (*Session, error) {
+	user, err := c.GetCurrentUser(ctx, withToken(opts, tokens.AccessToken)...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	session := c.NewSession(tokens.AccessToken, user)
+	session.RefreshToken = tokens.RefreshToken
+	session.ExpiresAt = tokens.expiresAt(time.Now())
+	return session, nil
+}
+
+// signIn makes session the client's signed-in session
+func (c *Client) signIn(session *Session) error {
+	err := c.setSession(session)
+	c.emitAuthEvent(AuthSignedIn)
+	return err
+}
+
+func (c *Client) setSession(session *Session) error {
 	c.mu.Lock()
-	c.user = user
+	err := c.storeTokensLocked(session.Token, session.RefreshToken, session.ExpiresAt)
+	c.user = session.User
+	c.mfaChallenge = nil
 	c.mu.Unlock()
 
-	return nil
+	c.persistUser(session.User)
+
+	return err
 }
 
 func (c *Client) Logout() error {
+	err := c.clearSession()
+	c.logger.Info("signed out")
+	c.emitAuthEvent(AuthSignedOut)
+	
+	return err
+}
+
+// clearSession forgets the client's tokens and user, in memory and in Storage
+func (c *Client) clearSession() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
-	c.token = ""
+
 	c.user = nil
-	
-	if c.storage != nil {
-		return c.storage.Delete("cocobase-token")
-	}
-	
-	return nil
+	c.mfaChallenge = nil
+	return c.storeTokensLocked("", "", time.Time{})
 }
 
-func (c *Client) GetCurrentUser(ctx context.Context) (*AppUser, error) {
-	if !c.IsAuthenticated() {
+func (c *Client) GetCurrentUser(ctx context.Context, opts ...CallOption) (*AppUser, error) {
+	if !c.authenticatedFor(ctx, opts) {
 		return nil, fmt.Errorf("user is not authenticated")
 	}
 	
-	resp, err := c.request(ctx, http.MethodGet, "/auth-collections/user", nil, true)
+	resp, err := c.request(ctx, Operation{Name: OpAuthGetUser}, http.MethodGet, "/auth-collections/user", nil, true, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var user AppUser
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	if err := c.decode(resp.Body, &user); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if c.storage != nil {
-		userData, _ := json.Marshal(user)
-		c.storage.Set("cocobase-user", string(userData))
+	// A user fetched with another token is not the client's user
+	if c.actsAsClientUser(ctx, opts) {
+		c.persistUser(&user)
 	}
 
 	return &user, nil
 }
 
-func (c *Client) UpdateUser(ctx context.Context, data map[string]interface{}, email, password *string) (*AppUser, error) {
-	if !c.IsAuthenticated() {
+func (c *Client) UpdateUser(ctx context.Context, data map[string]interface{}, email, password *string, opts ...CallOption) (*AppUser, error) {
+	if !c.authenticatedFor(ctx, opts) {
 		return nil, fmt.Errorf("user is not authenticated")
 	}
 
 	body := make(map[string]interface{})
+	shared := c.actsAsClientUser(ctx, opts)
 	
 	if data != nil {
 		c.mu.RLock()
 		currentData := make(map[string]interface{})
-		if c.user != nil {
+		if c.user != nil && shared {
 			currentData = c.user.Data
 		}
 		c.mu.RUnlock()
@@ -168,29 +224,39 @@ func (c *Client) UpdateUser(ctx context.Context, data map[string]interface{}, em
 		body["password"] = *password
 	}
 	
-	resp, err := c.request(ctx, http.MethodPatch, "/auth-collections/user", body, false)
+	resp, err := c.request(ctx, Operation{Name: OpAuthUpdateUser}, http.MethodPatch, "/auth-collections/user", body, false, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var user AppUser
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+	if err := c.decode(resp.Body, &user); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if !shared {
+		return &user, nil
+	}
+
 	c.mu.Lock()
 	c.user = &user
 	c.mu.Unlock()
 
-	if c.storage != nil {
-		userData, _ := json.Marshal(user)
-		c.storage.Set("cocobase-user", string(userData))
-	}
+	c.persistUser(&user)
+	c.emitAuthEvent(AuthUserUpdated)
 
 	return &user, nil
 }
 
+func (c *Client) persistUser(user *AppUser) {
+	if c.storage == nil {
+		return
+	}
+	userData, _ := json.Marshal(user)
+	c.logStorageError("set", "cocobase-user", c.storage.Set("cocobase-user", string(userData)))
+}
+
 func mergeData(current, updates map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 	
@@ -0,0 +1,106 @@
+package cocobase
+
+import (
+	"sync"
+)
+
+// AuthEventType identifies a change to the client's auth state
+type AuthEventType int
+
+const (
+	// AuthSignedIn follows Login, Register, or InitAuth restoring a session
+	AuthSignedIn AuthEventType = iota + 1
+	// AuthSignedOut follows Logout
+	AuthSignedOut
+	// AuthTokenRefreshed follows a new access token for the same user
+	AuthTokenRefreshed
+	// AuthUserUpdated follows UpdateUser changing the signed-in user
+	AuthUserUpdated
+	// AuthSessionExpired follows the server rejecting the refresh token; the
+	// client is signed out and the user must sign in again
+	AuthSessionExpired
+)
+
+func (t AuthEventType) String() string {
+	switch t {
+	case AuthSignedIn:
+		return "signed-in"
+	case AuthSignedOut:
+		return "signed-out"
+	case AuthTokenRefreshed:
+		return "token-refreshed"
+	case AuthUserUpdated:
+		return "user-updated"
+	case AuthSessionExpired:
+		return "session-expired"
+	default:
+		return "unknown"
+	}
+}
+
+// AuthEvent describes a change to the client's auth state
+type AuthEvent struct {
+	Type AuthEventType
+	// User is the signed-in user after the change, nil once signed out
+	User *AppUser
+}
+
+type authListener struct {
+	id int
+	fn func(AuthEvent)
+}
+
+// authListeners holds OnAuthStateChange callbacks in registration order
+type authListeners struct {
+	mu        sync.Mutex
+	nextID    int
+	listeners []authListener
+}
+
+// OnAuthStateChange calls fn after every change to the client's auth state
+// and returns a function that unsubscribes it. Listeners run synchronously,
+// in registration order, on the goroutine that made the change, so they
+// should return quickly. They may call the client.
+func (c *Client) OnAuthStateChange(fn func(AuthEvent)) func() {
+	l := &c.authListeners
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	id := l.nextID
+	l.listeners = append(l.listeners, authListener{id: id, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for i, listener := range l.listeners {
+				if listener.id == id {
+					l.listeners = append(l.listeners[:i:i], l.listeners[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// emitAuthEvent notifies listeners of a change. c.mu must not be held.
+func (c *Client) emitAuthEvent(t AuthEventType) {
+	l := &c.authListeners
+	l.mu.Lock()
+	listeners := l.listeners
+	l.mu.Unlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	c.mu.RLock()
+	event := AuthEvent{Type: t, User: c.user}
+	c.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener.fn(event)
+	}
+}
@@ -0,0 +1,347 @@
+package cocobase
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HeaderCache reports how a response was served: "hit", "revalidated"
+	// or "miss"
+	HeaderCache = "X-Cocobase-Cache"
+
+	DefaultCacheSize = 1000
+)
+
+// CachedResponse is a response body stored in a ResponseCache
+type CachedResponse struct {
+	Collection   string
+	Header       http.Header
+	Body         []byte
+	ETag         string
+	LastModified string
+	// StoredAt is when the response was last fetched or revalidated
+	StoredAt time.Time
+	// Expires is when the entry must be revalidated with the server
+	Expires time.Time
+}
+
+// ResponseCache stores GET responses for GetDocument, ListDocuments and
+// QueryDocuments. Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, entry *CachedResponse)
+	// Invalidate removes every entry belonging to collection
+	Invalidate(collection string)
+	Clear()
+}
+
+// CacheConfig configures response caching
+type CacheConfig struct {
+	// Cache stores the responses (default: an in-memory LRU of DefaultCacheSize entries)
+	Cache ResponseCache
+	// TTL is how long a response is served without contacting the server.
+	// Once it expires, entries with an ETag or Last-Modified are revalidated
+	// with a conditional request. Zero always revalidates.
+	TTL time.Duration
+}
+
+// ============================================
+// PER-CALL OPTIONS
+// ============================================
+
+type cacheContextKey struct{}
+
+type cacheOptions struct {
+	ttl    *time.Duration
+	bypass bool
+}
+
+// cacheOptionsFrom returns the per-call cache options that request attached
+// to the request context
+func cacheOptionsFrom(ctx context.Context) cacheOptions {
+	opts, _ := ctx.Value(cacheContextKey{}).(cacheOptions)
+	return opts
+}
+
+// WithCacheTTL overrides the cache TTL for this call: a cached response older
+// than ttl is revalidated, and a new one is stored for ttl
+func WithCacheTTL(ttl time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.cache.ttl = &ttl
+	}
+}
+
+// WithoutCache makes this call skip the cache
+func WithoutCache() CallOption {
+	return func(o *callOptions) {
+		o.cache.bypass = true
+	}
+}
+
+// ============================================
+// LRU CACHE
+// ============================================
+
+// LRUCache is an in-memory ResponseCache that evicts the least recently
+// used entry once full
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+// NewLRUCache creates an LRUCache holding at most size entries
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (l *LRUCache) Get(key string) (*CachedResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (l *LRUCache) Set(key string, entry *CachedResponse) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.entries[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruItem{key: key, entry: entry})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+func (l *LRUCache) Invalidate(collection string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, el := range l.entries {
+		if el.Value.(*lruItem).entry.Collection == collection {
+			l.order.Remove(el)
+			delete(l.entries, key)
+		}
+	}
+}
+
+func (l *LRUCache) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.entries = make(map[string]*list.Element)
+}
+
+// Len returns the number of cached entries
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// ============================================
+// MIDDLEWARE
+// ============================================
+
+type responseCache struct {
+	cache ResponseCache
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func newResponseCache(config CacheConfig) *responseCache {
+	if config.Cache == nil {
+		config.Cache = NewLRUCache(DefaultCacheSize)
+	}
+	return &responseCache{cache: config.Cache, ttl: config.TTL, now: time.Now}
+}
+
+func cacheableOperation(op Operation) bool {
+	switch op.Name {
+	case OpDocumentsGet, OpDocumentsList, OpDocumentsQuery:
+		return true
+	}
+	return false
+}
+
+func invalidatingOperation(op Operation) bool {
+	switch op.Name {
+	case OpDocumentsCreate, OpDocumentsUpdate, OpDocumentsDelete:
+		return op.Collection != ""
+	}
+	return false
+}
+
+// cacheKey identifies a request by its canonical path and query and by who
+// is asking, so users never see each other's cached responses
+func cacheKey(req *http.Request) string {
+	identity := sha256.Sum256([]byte(req.Header.Get(HeaderAPIKey) + "\x00" + req.Header.Get(HeaderAuthorization)))
+	// Query().Encode() sorts parameters, so equivalent queries share a key
+	return req.Method + " " + req.URL.Path + "?" + req.URL.Query().Encode() + " " + hex.EncodeToString(identity[:16])
+}
+
+func (rc *responseCache) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request, op Operation) (*http.Response, error) {
+		if invalidatingOperation(op) {
+			resp, err := next(req, op)
+			// Writes through this client make cached reads of the collection stale
+			if err == nil && resp.StatusCode < 400 {
+				rc.cache.Invalidate(op.Collection)
+			}
+			return resp, err
+		}
+
+		opts := cacheOptionsFrom(req.Context())
+		if req.Method != http.MethodGet || !cacheableOperation(op) || opts.bypass {
+			return next(req, op)
+		}
+
+		ttl := rc.ttl
+		if opts.ttl != nil {
+			ttl = *opts.ttl
+		}
+
+		key := cacheKey(req)
+		entry, ok := rc.cache.Get(key)
+		if ok && entry.fresh(rc.now(), opts.ttl) {
+			return entry.response(req, "hit"), nil
+		}
+
+		if ok {
+			req = req.Clone(req.Context())
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+
+		resp, err := next(req, op)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok && resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			refreshed := *entry
+			refreshed.StoredAt = rc.now()
+			refreshed.Expires = refreshed.StoredAt.Add(ttl)
+			if etag := resp.Header.Get("ETag"); etag != "" {
+				refreshed.ETag = etag
+			}
+			rc.cache.Set(key, &refreshed)
+			return refreshed.response(req, "revalidated"), nil
+		}
+
+		if resp.StatusCode != http.StatusOK || !storable(resp) {
+			return resp, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.Header.Set(HeaderCache, "miss")
+
+		now := rc.now()
+		stored := &CachedResponse{
+			Collection:   op.Collection,
+			Header:       resp.Header.Clone(),
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			StoredAt:     now,
+			Expires:      now.Add(ttl),
+		}
+		// Without a TTL or validators the entry could never be used
+		if ttl > 0 || stored.ETag != "" || stored.LastModified != "" {
+			rc.cache.Set(key, stored)
+		}
+
+		return resp, nil
+	}
+}
+
+// fresh reports whether the entry can be served without the server, using
+// the per-call TTL instead of the stored expiry when one is given
+func (e *CachedResponse) fresh(now time.Time, ttl *time.Duration) bool {
+	if ttl != nil {
+		return now.Before(e.StoredAt.Add(*ttl))
+	}
+	return now.Before(e.Expires)
+}
+
+func storable(resp *http.Response) bool {
+	return !strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store")
+}
+
+func (e *CachedResponse) response(req *http.Request, status string) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(HeaderCache, status)
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// InvalidateCache drops cached responses for collection, or every cached
+// response when collection is empty
+func (c *Client) InvalidateCache(collection string) {
+	if c.cache == nil {
+		return
+	}
+	if collection == "" {
+		c.cache.cache.Clear()
+		return
+	}
+	c.cache.cache.Invalidate(collection)
+}
@@ -0,0 +1,156 @@
+package cocobase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks a 409 Conflict as the server replaying
+	// an earlier request with the same Idempotency-Key
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// CallOption customizes a single call without changing the client
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout        time.Duration
+	headers        http.Header
+	token          *string
+	noAuth         bool
+	idempotencyKey string
+	cache          cacheOptions
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	o := &callOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithCallTimeout bounds this call, including reading the response
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithCallHeader adds a header to this call, e.g. a trace ID
+func WithCallHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Add(key, value)
+	}
+}
+
+// WithBearerToken makes this call as the user owning token instead of the
+// client's signed-in user
+func WithBearerToken(token string) CallOption {
+	return func(o *callOptions) {
+		o.token = &token
+		o.noAuth = false
+	}
+}
+
+// WithoutAuth sends this call without an Authorization header
+func WithoutAuth() CallOption {
+	return func(o *callOptions) {
+		o.noAuth = true
+		o.token = nil
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header so the server can
+// recognise a retried write
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// authToken returns the token to send, falling back to the client's token
+func (o *callOptions) authToken(clientToken string) string {
+	switch {
+	case o.noAuth:
+		return ""
+	case o.token != nil:
+		return *o.token
+	default:
+		return clientToken
+	}
+}
+
+// overridesAuth reports whether the call does not run as the client's user
+func (o *callOptions) overridesAuth() bool {
+	return o.noAuth || o.token != nil
+}
+
+// withTimeout applies the call timeout to ctx. The returned cancel function
+// must be called once the response body is no longer needed.
+func (o *callOptions) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// cancelOnClose releases a call's timeout once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// withToken returns a copy of opts that authenticates with token
+func withToken(opts []CallOption, token string) []CallOption {
+	return append(append([]CallOption(nil), opts...), WithBearerToken(token))
+}
+
+// withoutAuth returns a copy of opts that sends no token, for requests that
+// carry their own credentials
+func withoutAuth(opts []CallOption) []CallOption {
+	return append(append([]CallOption(nil), opts...), WithoutAuth())
+}
+
+// resolveToken picks the token for a call: a call option wins over a token
+// carried by ctx, which wins over the client's own token
+func (c *Client) resolveToken(ctx context.Context, o *callOptions) string {
+	if token, ok := UserTokenFromContext(ctx); ok {
+		return o.authToken(token)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return o.authToken(c.token)
+}
+
+// authenticatedFor reports whether a call with ctx and opts would send a token
+func (c *Client) authenticatedFor(ctx context.Context, opts []CallOption) bool {
+	return c.resolveToken(ctx, newCallOptions(opts)) != ""
+}
+
+// actsAsClientUser reports whether a call runs as the client's signed-in
+// user, and so may update the client's user state
+func (c *Client) actsAsClientUser(ctx context.Context, opts []CallOption) bool {
+	return c.usesClientToken(ctx, newCallOptions(opts))
+}
+
+func (c *Client) usesClientToken(ctx context.Context, o *callOptions) bool {
+	if _, ok := UserTokenFromContext(ctx); ok {
+		return false
+	}
+	return !o.overridesAuth()
+}
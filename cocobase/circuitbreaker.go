@@ -0,0 +1,259 @@
+package cocobase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of the client's circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all requests fast with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker. Transport errors,
+// timeouts and 5xx responses count as failures.
+type CircuitBreakerConfig struct {
+	// FailureRatio trips the breaker when failures/requests reaches it (default 0.5)
+	FailureRatio float64
+	// MinRequests is the number of requests in a window before the ratio
+	// is considered (default 10)
+	MinRequests int
+	// Window is the period over which requests are counted (default 60s)
+	Window time.Duration
+	// OpenTimeout is how long the breaker stays open before probing (default 30s)
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of probe requests allowed, and required
+	// to succeed, before closing again (default 1)
+	HalfOpenProbes int
+	// OnStateChange is called after every state transition, one at a time
+	// and in order, on the goroutine of a request that was in progress; it
+	// should return quickly
+	OnStateChange func(from, to CircuitState)
+}
+
+type circuitBreaker struct {
+	mu     sync.Mutex
+	config CircuitBreakerConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	state          CircuitState
+	openedAt       time.Time
+	windowStart    time.Time
+	requests       int
+	failures       int
+	probesInFlight int
+	probeSuccesses int
+
+	// pending holds transitions not yet passed to OnStateChange
+	pending   []stateChange
+	notifying bool
+}
+
+type stateChange struct {
+	from, to CircuitState
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig, logger *slog.Logger) *circuitBreaker {
+	if config.FailureRatio <= 0 {
+		config.FailureRatio = 0.5
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 10
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+	return &circuitBreaker{config: config, logger: logger, now: time.Now}
+}
+
+// State returns the current state, moving from open to half-open once the
+// open timeout has passed
+func (cb *circuitBreaker) State() CircuitState {
+	defer cb.notify()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance(cb.now())
+	return cb.state
+}
+
+// allow reports whether a request may proceed and whether it is a probe
+func (cb *circuitBreaker) allow() (probe bool, err error) {
+	defer cb.notify()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.advance(cb.now())
+
+	switch cb.state {
+	case CircuitOpen:
+		return false, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probesInFlight >= cb.config.HalfOpenProbes-cb.probeSuccesses {
+			return false, ErrCircuitOpen
+		}
+		cb.probesInFlight++
+		return true, nil
+	}
+	return false, nil
+}
+
+// record reports the outcome of a request. ignored outcomes (e.g. the
+// caller cancelled) release a probe slot without affecting the state.
+func (cb *circuitBreaker) record(probe, failed, ignored bool) {
+	defer cb.notify()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.now()
+
+	if probe {
+		cb.probesInFlight--
+		if ignored || cb.state != CircuitHalfOpen {
+			return
+		}
+		if failed {
+			cb.transition(CircuitOpen, now)
+			return
+		}
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.config.HalfOpenProbes {
+			cb.transition(CircuitClosed, now)
+		}
+		return
+	}
+
+	if ignored || cb.state != CircuitClosed {
+		return
+	}
+
+	if now.Sub(cb.windowStart) >= cb.config.Window {
+		cb.windowStart = now
+		cb.requests, cb.failures = 0, 0
+	}
+	cb.requests++
+	if failed {
+		cb.failures++
+	}
+
+	if cb.requests >= cb.config.MinRequests &&
+		float64(cb.failures)/float64(cb.requests) >= cb.config.FailureRatio {
+		cb.transition(CircuitOpen, now)
+	}
+}
+
+func (cb *circuitBreaker) advance(now time.Time) {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.transition(CircuitHalfOpen, now)
+	}
+}
+
+func (cb *circuitBreaker) transition(to CircuitState, now time.Time) {
+	from := cb.state
+	if from == to {
+		return
+	}
+
+	cb.state = to
+	cb.probesInFlight, cb.probeSuccesses = 0, 0
+	switch to {
+	case CircuitOpen:
+		cb.openedAt = now
+	case CircuitClosed:
+		cb.windowStart = now
+		cb.requests, cb.failures = 0, 0
+	}
+
+	level := slog.LevelInfo
+	if to == CircuitOpen {
+		level = slog.LevelWarn
+	}
+	cb.logger.Log(context.Background(), level, "circuit breaker state changed",
+		"from", from.String(), "to", to.String())
+
+	if cb.config.OnStateChange != nil {
+		cb.pending = append(cb.pending, stateChange{from, to})
+	}
+}
+
+// notify passes pending transitions to OnStateChange in order, outside the
+// lock so the callback may query the state. Only one goroutine delivers at a
+// time; transitions queued meanwhile are left to it.
+func (cb *circuitBreaker) notify() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.notifying {
+		return
+	}
+	cb.notifying = true
+	for len(cb.pending) > 0 {
+		change := cb.pending[0]
+		cb.pending = cb.pending[1:]
+
+		cb.mu.Unlock()
+		cb.config.OnStateChange(change.from, change.to)
+		cb.mu.Lock()
+	}
+	cb.notifying = false
+}
+
+func (cb *circuitBreaker) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request, op Operation) (*http.Response, error) {
+		probe, err := cb.allow()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(req, op)
+
+		failed := err != nil || resp.StatusCode >= 500
+		ignored := errors.Is(err, context.Canceled)
+		cb.record(probe, failed, ignored)
+
+		return resp, err
+	}
+}
+
+// CircuitState returns the state of the circuit breaker, for health checks.
+// It is always CircuitClosed when no breaker is configured.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State()
+}
@@ -3,7 +3,6 @@ package cocobase
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,30 +15,118 @@ func NewClient(config Config) *Client {
 	}
 	
 	if config.HTTPClient == nil {
-		config.HTTPClient = &http.Client{
-			Timeout: DefaultTimeout,
-		}
+		config.HTTPClient = newHTTPClient(config)
+	}
+	
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
 	}
 
-	return &Client{
+	c := &Client{
 		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
 		apiKey:     config.APIKey,
 		httpClient: config.HTTPClient,
 		storage:    config.Storage,
+
+		clientSideGeo: config.ClientSideGeo,
+		logger:        newLogger(config.Logger),
+		userAgent:     config.UserAgent,
+		headers:       config.Headers.Clone(),
+		retry:         normalizeRetry(config.Retry),
+		dedupe:        newDedupeStore(config.DedupeWindow),
+
+		compressRequests:     config.CompressRequests,
+		compressionThreshold: config.CompressionThreshold,
+		maxResponseSize:      config.MaxResponseSize,
+		codec:                config.Codec,
+
+		autoRefresh:   !config.DisableTokenRefresh,
+		refreshLeeway: config.RefreshLeeway,
+	}
+	
+	if c.codec == nil {
+		c.codec = JSONCodec{}
+	}
+	
+	if c.refreshLeeway <= 0 {
+		c.refreshLeeway = DefaultRefreshLeeway
+	}
+	
+	if c.compressionThreshold <= 0 {
+		c.compressionThreshold = DefaultCompressionThreshold
+	}
+	
+	// Credentials and the content type are only ever set by the client
+	for key := range c.headers {
+		switch {
+		case strings.EqualFold(key, HeaderAPIKey),
+			strings.EqualFold(key, HeaderAuthorization),
+			strings.EqualFold(key, "Content-Type"):
+			delete(c.headers, key)
+		}
+	}
+
+	// User middleware sees each logical call; the built-in middleware below
+	// runs closer to the wire
+	middleware := append([]Middleware{}, config.Middleware...)
+	if config.Cache != nil {
+		c.cache = newResponseCache(*config.Cache)
+		middleware = append(middleware, c.cache.middleware)
 	}
+	if config.CoalesceReads {
+		middleware = append(middleware, newCoalescer().middleware)
+	}
+	if config.RateLimit != nil {
+		middleware = append(middleware, newRateLimiter(*config.RateLimit).middleware)
+	}
+	// Inside the rate limiter, so time spent waiting for a local slot is
+	// never counted against the server
+	if config.CircuitBreaker != nil {
+		c.breaker = newCircuitBreaker(*config.CircuitBreaker, c.logger)
+		middleware = append(middleware, c.breaker.middleware)
+	}
+	middleware = append(middleware, c.logRequest)
+
+	c.roundTrip = chainMiddleware(c.send, middleware)
+
+	return c
 }
 
+// SetToken sets the access token. The refresh token is dropped unless the
+// token is unchanged, so it can never bring back an earlier session; use
+// SetTokens to set both. The expiry is read from the token's exp claim when
+// it is a JWT.
 func (c *Client) SetToken(token string) error {
+	c.mu.RLock()
+	refreshToken := ""
+	if token == c.token {
+		refreshToken = c.refreshToken
+	}
+	c.mu.RUnlock()
+
+	return c.SetTokens(token, refreshToken)
+}
+
+// SetTokens sets the access and refresh tokens, e.g. from a session issued
+// elsewhere
+func (c *Client) SetTokens(token, refreshToken string) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	previous := c.token
+	expiry, _ := jwtExpiry(token)
+	err := c.storeTokensLocked(token, refreshToken, expiry)
+	c.mu.Unlock()
 	
-	c.token = token
-	
-	if c.storage != nil {
-		return c.storage.Set("cocobase-token", token)
+	switch {
+	case token == previous:
+	case token == "":
+		c.emitAuthEvent(AuthSignedOut)
+	case previous == "":
+		c.emitAuthEvent(AuthSignedIn)
+	default:
+		c.emitAuthEvent(AuthTokenRefreshed)
 	}
 	
-	return nil
+	return err
 }
 
 func (c *Client) GetToken() string {
@@ -71,10 +158,12 @@ func (c *Client) HasRole(role string) bool {
 	return false
 }
 
-func (c *Client) request(ctx context.Context, method, path string, body interface{}, useDataKey bool) (*http.Response, error) {
+func (c *Client) request(ctx context.Context, op Operation, method, path string, body interface{}, useDataKey bool, opts ...CallOption) (*http.Response, error) {
 	url := c.baseURL + path
+	callOpts := newCallOptions(opts)
 	
-	var bodyReader io.Reader
+	var jsonData []byte
+	var contentEncoding string
 	if body != nil {
 		var data interface{}
 		if useDataKey {
@@ -83,11 +172,82 @@ func (c *Client) request(ctx context.Context, method, path string, body interfac
 			data = body
 		}
 		
-		jsonData, err := json.Marshal(data)
+		var err error
+		jsonData, err = c.codec.Marshal(data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(jsonData)
+		
+		jsonData, contentEncoding, err = c.compressBody(jsonData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := callOpts.withTimeout(ctx)
+	if callOpts.cache != (cacheOptions{}) {
+		ctx = context.WithValue(ctx, cacheContextKey{}, callOpts.cache)
+	}
+	c.refreshIfExpiring(ctx, callOpts)
+
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		op.Attempt = attempt
+		
+		req, err := c.newRequest(ctx, method, url, jsonData, contentEncoding, callOpts)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		resp, err := c.roundTrip(req, op)
+		
+		if delay, ok := c.retryDelay(ctx, req, resp, err, attempt); ok {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				cancel()
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+			continue
+		}
+		
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		
+		// Retry once with a fresh token if the server rejected ours
+		if !refreshed && c.refreshAfterUnauthorized(ctx, req, resp, callOpts) {
+			refreshed = true
+			resp.Body.Close()
+			continue
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+		if resp.StatusCode >= 400 {
+			defer resp.Body.Close()
+			bodyBytes, _ := io.ReadAll(resp.Body)
+			
+			return nil, &APIError{
+				StatusCode: resp.StatusCode,
+				Method:     method,
+				URL:        url,
+				Body:       string(bodyBytes),
+				Suggestion: getErrorSuggestion(resp.StatusCode, method),
+				Header:     resp.Header,
+			}
+		}
+
+		return resp, nil
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, url string, body []byte, contentEncoding string, callOpts *callOptions) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
@@ -95,36 +255,48 @@ func (c *Client) request(ctx context.Context, method, path string, body interfac
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", ContentTypeJSON)
+	for key, values := range c.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range callOpts.headers {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	req.Header.Set(HeaderUserAgent, c.userAgent)
+	req.Header.Set("Content-Type", c.codec.ContentType())
+	req.Header.Set(HeaderAccept, c.codec.ContentType())
+	// Set explicitly, so responses are decompressed by decodeBody rather
+	// than transparently by the transport
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	if contentEncoding != "" {
+		req.Header.Set(HeaderContentEncoding, contentEncoding)
+	}
 	
 	if c.apiKey != "" {
 		req.Header.Set(HeaderAPIKey, c.apiKey)
 	}
 	
-	c.mu.RLock()
-	token := c.token
-	c.mu.RUnlock()
+	token := c.resolveToken(ctx, callOpts)
 	
 	if token != "" {
 		req.Header.Set(HeaderAuthorization, "Bearer "+token)
 	}
+	
+	if callOpts.idempotencyKey != "" {
+		req.Header.Set(HeaderIdempotencyKey, callOpts.idempotencyKey)
+	}
 
+	return req, nil
+}
+
+func (c *Client) send(req *http.Request, op Operation) (*http.Response, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		
-		return nil, &APIError{
-			StatusCode: resp.StatusCode,
-			Method:     method,
-			URL:        url,
-			Body:       string(bodyBytes),
-			Suggestion: getErrorSuggestion(resp.StatusCode, method),
-		}
+	if err := c.decodeBody(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
 	}
 
 	return resp, nil
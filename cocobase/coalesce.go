@@ -0,0 +1,116 @@
+package cocobase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// coalescer shares one round-trip between identical concurrent GET requests.
+// The shared request runs detached from any single caller's context and is
+// only cancelled once every caller waiting on it has given up.
+type coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{flights: make(map[string]*flight)}
+}
+
+func (co *coalescer) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request, op Operation) (*http.Response, error) {
+		// A shared flight buffers the whole body, defeating streaming
+		if req.Method != http.MethodGet || op.Name == OpDocumentsStream {
+			return next(req, op)
+		}
+
+		key := coalesceKey(req)
+
+		co.mu.Lock()
+		f, ok := co.flights[key]
+		if !ok {
+			ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+			f = &flight{done: make(chan struct{}), cancel: cancel}
+			co.flights[key] = f
+			go co.run(key, f, next, req.WithContext(ctx), op)
+		}
+		f.waiters++
+		co.mu.Unlock()
+
+		select {
+		case <-f.done:
+			if f.err != nil {
+				return nil, f.err
+			}
+			return f.response(req), nil
+		case <-req.Context().Done():
+			co.leave(key, f)
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (co *coalescer) run(key string, f *flight, next RoundTripFunc, req *http.Request, op Operation) {
+	defer f.cancel()
+
+	resp, err := next(req, op)
+	if err == nil {
+		f.body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	f.resp, f.err = resp, err
+
+	co.mu.Lock()
+	if co.flights[key] == f {
+		delete(co.flights, key)
+	}
+	co.mu.Unlock()
+
+	close(f.done)
+}
+
+// leave drops a caller whose context is done, cancelling the shared request
+// when nobody is waiting for it any more
+func (co *coalescer) leave(key string, f *flight) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+	// Later callers start a fresh request rather than joining a cancelled one
+	if co.flights[key] == f {
+		delete(co.flights, key)
+	}
+	f.cancel()
+}
+
+// coalesceKey extends the cache key with the conditional headers, so a
+// revalidation that may get an empty 304 is never shared with a plain read
+func coalesceKey(req *http.Request) string {
+	return cacheKey(req) + "\x00" + req.Header.Get("If-None-Match") + "\x00" + req.Header.Get("If-Modified-Since")
+}
+
+// response gives each caller its own copy of the shared response
+func (f *flight) response(req *http.Request) *http.Response {
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	resp.ContentLength = int64(len(f.body))
+	resp.Request = req
+	return &resp
+}
@@ -0,0 +1,56 @@
+package cocobase
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+const HeaderAccept = "Accept"
+
+// Codec encodes request bodies and decodes responses. Implement it to use a
+// faster JSON library, or another format such as MessagePack if the server
+// supports it.
+type Codec interface {
+	// ContentType is sent as the Content-Type and Accept headers
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Decode(r io.Reader, v interface{}) error
+}
+
+// JSONCodec is the default codec. Numbers in document data decode as
+// json.Number, so large integers and decimals keep their exact value; use
+// its Int64, Float64 or String methods to read them.
+type JSONCodec struct {
+	// UseFloat64 decodes numbers as float64 instead, as encoding/json does
+	// by default
+	UseFloat64 bool
+}
+
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return c.newDecoder(r).Decode(v)
+}
+
+func (c JSONCodec) newDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	if !c.UseFloat64 {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+func (c *Client) decode(r io.Reader, v interface{}) error {
+	return c.codec.Decode(r, v)
+}
+
+func (c *Client) unmarshal(data []byte, v interface{}) error {
+	return c.codec.Decode(bytes.NewReader(data), v)
+}
@@ -0,0 +1,111 @@
+package cocobase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderContentEncoding = "Content-Encoding"
+	HeaderAcceptEncoding  = "Accept-Encoding"
+
+	// DefaultCompressionThreshold is the smallest request body gzipped when
+	// CompressRequests is set
+	DefaultCompressionThreshold = 1024
+)
+
+// ErrResponseTooLarge is returned while reading a response body larger than
+// Config.MaxResponseSize
+var ErrResponseTooLarge = errors.New("response exceeds the maximum size")
+
+// compressBody gzips data when request compression is enabled and data is
+// large enough to be worth it, returning the body and its Content-Encoding
+func (c *Client) compressBody(data []byte) ([]byte, string, error) {
+	if !c.compressRequests || len(data) < c.compressionThreshold {
+		return data, "", nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, "", fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), "gzip", nil
+}
+
+// decodeBody decompresses a gzip response and enforces the maximum response
+// size on the decompressed bytes, so a small compressed body cannot expand
+// without bound
+func (c *Client) decodeBody(resp *http.Response) error {
+	if c.maxResponseSize > 0 && resp.ContentLength > c.maxResponseSize &&
+		resp.Header.Get(HeaderContentEncoding) == "" {
+		return fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, resp.ContentLength)
+	}
+
+	if strings.EqualFold(resp.Header.Get(HeaderContentEncoding), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to decompress response: %w", err)
+		}
+		resp.Body = &readCloser{Reader: zr, close: resp.Body.Close}
+		resp.Header.Del(HeaderContentEncoding)
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+
+	if c.maxResponseSize > 0 {
+		resp.Body = &limitedBody{body: resp.Body, remaining: c.maxResponseSize}
+	}
+
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.close()
+}
+
+// limitedBody fails with ErrResponseTooLarge instead of silently truncating
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Only an error if there is more to read
+		var probe [1]byte
+		n, err := l.body.Read(probe[:])
+		switch {
+		case n > 0:
+			return 0, ErrResponseTooLarge
+		case err == nil:
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
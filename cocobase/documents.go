@@ -2,75 +2,103 @@ package cocobase
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
-func (c *Client) GetDocument(ctx context.Context, collection, docID string) (*Document, error) {
+func (c *Client) GetDocument(ctx context.Context, collection, docID string, opts ...CallOption) (*Document, error) {
 	path := fmt.Sprintf("/collections/%s/documents/%s", collection, docID)
 	
-	resp, err := c.request(ctx, http.MethodGet, path, nil, true)
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsGet, Collection: collection, DocumentID: docID}, http.MethodGet, path, nil, true, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var doc Document
-	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+	if err := c.decode(resp.Body, &doc); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return &doc, nil
 }
 
-func (c *Client) CreateDocument(ctx context.Context, collection string, data map[string]interface{}) (*Document, error) {
+func (c *Client) CreateDocument(ctx context.Context, collection string, data map[string]interface{}, opts ...CallOption) (*Document, error) {
 	path := fmt.Sprintf("/collections/documents?collection=%s", collection)
 	
-	resp, err := c.request(ctx, http.MethodPost, path, data, true)
+	// One key per logical create, reused by every retry
+	key := newCallOptions(opts).idempotencyKey
+	if key == "" {
+		key = newIdempotencyKey()
+		opts = append(opts[:len(opts):len(opts)], WithIdempotencyKey(key))
+	}
+	
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsCreate, Collection: collection}, http.MethodPost, path, data, true, opts...)
 	if err != nil {
+		if doc, ok := c.duplicateCreate(collection, key, err); ok {
+			return doc, nil
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var doc Document
-	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+	if err := c.decode(resp.Body, &doc); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.dedupe.put(collection, key, doc)
+
+	if idx := c.localSearchIndex(collection); idx != nil {
+		idx.Add(doc)
+	}
+
 	return &doc, nil
 }
 
-func (c *Client) UpdateDocument(ctx context.Context, collection, docID string, data map[string]interface{}) (*Document, error) {
+func (c *Client) UpdateDocument(ctx context.Context, collection, docID string, data map[string]interface{}, opts ...CallOption) (*Document, error) {
 	path := fmt.Sprintf("/collections/%s/documents/%s", collection, docID)
 	
-	resp, err := c.request(ctx, http.MethodPatch, path, data, true)
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsUpdate, Collection: collection, DocumentID: docID}, http.MethodPatch, path, data, true, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var doc Document
-	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+	if err := c.decode(resp.Body, &doc); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if idx := c.localSearchIndex(collection); idx != nil {
+		idx.Add(doc)
+	}
+
 	return &doc, nil
 }
 
-func (c *Client) DeleteDocument(ctx context.Context, collection, docID string) error {
+func (c *Client) DeleteDocument(ctx context.Context, collection, docID string, opts ...CallOption) error {
 	path := fmt.Sprintf("/collections/%s/documents/%s", collection, docID)
 	
-	resp, err := c.request(ctx, http.MethodDelete, path, nil, true)
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsDelete, Collection: collection, DocumentID: docID}, http.MethodDelete, path, nil, true, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if idx := c.localSearchIndex(collection); idx != nil {
+		idx.Remove(docID)
+	}
+
 	return nil
 }
 
-func (c *Client) ListDocuments(ctx context.Context, collection string, query *QueryBuilder) ([]Document, error) {
+func (c *Client) ListDocuments(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) ([]Document, error) {
+	if query != nil && query.hasGeo() {
+		return c.listDocumentsGeo(ctx, collection, query, opts...)
+	}
+
 	path := fmt.Sprintf("/collections/%s/documents", collection)
 	
 	if query != nil {
@@ -80,37 +108,80 @@ func (c *Client) ListDocuments(ctx context.Context, collection string, query *Qu
 		}
 	}
 	
-	resp, err := c.request(ctx, http.MethodGet, path, nil, true)
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsList, Collection: collection}, http.MethodGet, path, nil, true, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var docs []Document
-	if err := json.NewDecoder(resp.Body).Decode(&docs); err != nil {
+	if err := c.decode(resp.Body, &docs); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return docs, nil
 }
 
-func (c *Client) QueryDocuments(ctx context.Context, collection, rawQuery string) ([]Document, error) {
+func (c *Client) QueryDocuments(ctx context.Context, collection, rawQuery string, opts ...CallOption) ([]Document, error) {
 	path := fmt.Sprintf("/collections/%s/documents", collection)
 	
 	if rawQuery != "" {
 		path += "?" + rawQuery
 	}
 	
-	resp, err := c.request(ctx, http.MethodGet, path, nil, true)
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsQuery, Collection: collection}, http.MethodGet, path, nil, true, opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var docs []Document
-	if err := json.NewDecoder(resp.Body).Decode(&docs); err != nil {
+	if err := c.decode(resp.Body, &docs); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return docs, nil
 }
+
+// geoPageSize is the page size used to fetch candidates for geo queries
+// evaluated locally
+const geoPageSize = 100
+
+// listDocumentsGeo sends geo queries to the server unless ClientSideGeo is
+// set, and falls back to evaluating them locally when the server rejects them.
+// Locally evaluated queries fetch every document matching the other filters,
+// a page at a time.
+func (c *Client) listDocumentsGeo(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) ([]Document, error) {
+	if !c.clientSideGeo {
+		docs, err := c.QueryDocuments(ctx, collection, query.Build(), opts...)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !geoUnsupported(apiErr.StatusCode) {
+			return docs, err
+		}
+	}
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	var docs []Document
+	candidates := query.withoutGeo()
+	for offset := 0; ; offset += geoPageSize {
+		page, err := c.ListDocuments(ctx, collection, candidates.Limit(geoPageSize).Offset(offset), opts...)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, page...)
+		if len(page) < geoPageSize {
+			break
+		}
+	}
+
+	return query.Apply(docs), nil
+}
+
+func geoUnsupported(status int) bool {
+	return status == http.StatusBadRequest ||
+		status == http.StatusUnprocessableEntity ||
+		status == http.StatusNotImplemented
+}
@@ -1,6 +1,9 @@
 package cocobase
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+)
 
 type APIError struct {
 	StatusCode int
@@ -8,6 +11,8 @@ type APIError struct {
 	URL        string
 	Body       string
 	Suggestion string
+	// Header holds the response headers, e.g. Retry-After
+	Header http.Header
 }
 
 func (e *APIError) Error() string {
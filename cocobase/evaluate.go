@@ -0,0 +1,522 @@
+package cocobase
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryOperators lists every filter key suffix understood by the query
+// builder. The text after the last underscore must equal one of them exactly.
+var queryOperators = []string{
+	"withinpolygon",
+	"withinbox",
+	"near",
+	"arraycontainsany",
+	"arraycontainsall",
+	"arraycontains",
+	"startswithcs",
+	"endswithcs",
+	"containscs",
+	"startswith",
+	"endswith",
+	"contains",
+	"isnull",
+	"exists",
+	"notin",
+	"regex",
+	"size",
+	"gte",
+	"lte",
+	"ne",
+	"gt",
+	"lt",
+	"in",
+}
+
+// ============================================
+// IN-MEMORY EVALUATION
+// ============================================
+
+// Evaluate reports whether doc satisfies every filter and OR group in the
+// query, using the same semantics the server applies. A filter with an
+// invalid regular expression matches nothing; Validate reports it.
+func (qb *QueryBuilder) Evaluate(doc Document) bool {
+	return qb.matcher().match(doc)
+}
+
+// Validate reports filters that cannot be evaluated, such as an invalid
+// regular expression
+func (qb *QueryBuilder) Validate() error {
+	return qb.matcher().err
+}
+
+// matcher evaluates a query against documents with its regular expressions
+// compiled once
+type matcher struct {
+	qb      *QueryBuilder
+	regexps map[string]*regexp.Regexp
+	err     error
+}
+
+func (qb *QueryBuilder) matcher() *matcher {
+	m := &matcher{qb: qb, regexps: make(map[string]*regexp.Regexp)}
+	compile := func(key, value string) {
+		if _, op := splitFilterKey(key); op != "regex" {
+			return
+		}
+		if _, ok := m.regexps[value]; ok {
+			return
+		}
+		re, err := regexp.Compile(value)
+		if err != nil {
+			if m.err == nil {
+				m.err = fmt.Errorf("invalid regular expression for %s: %w", key, err)
+			}
+			return
+		}
+		m.regexps[value] = re
+	}
+
+	for key, value := range qb.filters {
+		compile(key, value)
+	}
+	for _, filters := range qb.orFilters {
+		for _, filter := range filters {
+			if key, value, ok := parseOrFilter(filter); ok {
+				compile(key, value)
+			}
+		}
+	}
+	return m
+}
+
+func (m *matcher) match(doc Document) bool {
+	for key, value := range m.qb.filters {
+		field, op := splitFilterKey(key)
+		if !m.matchFilter(doc, field, op, value) {
+			return false
+		}
+	}
+
+	for _, filters := range m.qb.orFilters {
+		if len(filters) == 0 {
+			continue
+		}
+
+		matched := false
+		for _, filter := range filters {
+			key, value, ok := parseOrFilter(filter)
+			if !ok {
+				continue
+			}
+			field, op := splitFilterKey(key)
+			if m.matchFilter(doc, field, op, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply filters, sorts and paginates docs in memory like Evaluate. The input
+// slice is not modified.
+func (qb *QueryBuilder) Apply(docs []Document) []Document {
+	m := qb.matcher()
+	result := make([]Document, 0, len(docs))
+	for _, doc := range docs {
+		if m.match(doc) {
+			result = append(result, doc)
+		}
+	}
+
+	if qb.sortOrigin != nil {
+		sortByDistance(result, qb.sort, *qb.sortOrigin, qb.order == "desc")
+	} else if qb.sort != "" {
+		desc := qb.order == "desc"
+		sort.SliceStable(result, func(i, j int) bool {
+			a, _ := lookupField(result[i], qb.sort)
+			b, _ := lookupField(result[j], qb.sort)
+			cmp := compareFieldValues(a, b)
+			if desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+	}
+
+	if qb.offset > 0 {
+		if qb.offset >= len(result) {
+			return []Document{}
+		}
+		result = result[qb.offset:]
+	}
+	if qb.limit > 0 && qb.limit < len(result) {
+		result = result[:qb.limit]
+	}
+
+	return result
+}
+
+func splitFilterKey(key string) (field, op string) {
+	idx := strings.LastIndex(key, "_")
+	if idx <= 0 {
+		return key, ""
+	}
+
+	suffix := key[idx+1:]
+	for _, candidate := range queryOperators {
+		if suffix == candidate {
+			return key[:idx], candidate
+		}
+	}
+
+	return key, ""
+}
+
+func parseOrFilter(filter string) (key, value string, ok bool) {
+	end := strings.Index(filter, "]")
+	if !strings.HasPrefix(filter, "[or") || end < 0 {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(filter[end+1:], "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+func (m *matcher) matchFilter(doc Document, field, op, value string) bool {
+	// Multi-field search: "name__or__email_contains"
+	if strings.Contains(field, "__or__") {
+		for _, f := range strings.Split(field, "__or__") {
+			if m.matchFilter(doc, f, op, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	actual, exists := lookupField(doc, field)
+
+	switch op {
+	case "":
+		return exists && valueEquals(actual, value)
+	case "ne":
+		return !exists || !valueEquals(actual, value)
+	case "gt", "gte", "lt", "lte":
+		if !exists || actual == nil {
+			return false
+		}
+		cmp, ok := compareToString(actual, value)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "gt":
+			return cmp > 0
+		case "gte":
+			return cmp >= 0
+		case "lt":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	case "contains":
+		return exists && strings.Contains(strings.ToLower(stringify(actual)), strings.ToLower(value))
+	case "startswith":
+		return exists && strings.HasPrefix(strings.ToLower(stringify(actual)), strings.ToLower(value))
+	case "endswith":
+		return exists && strings.HasSuffix(strings.ToLower(stringify(actual)), strings.ToLower(value))
+	case "containscs":
+		return exists && strings.Contains(stringify(actual), value)
+	case "startswithcs":
+		return exists && strings.HasPrefix(stringify(actual), value)
+	case "endswithcs":
+		return exists && strings.HasSuffix(stringify(actual), value)
+	case "regex":
+		if !exists || actual == nil {
+			return false
+		}
+		re, ok := m.regexps[value]
+		return ok && re.MatchString(stringify(actual))
+	case "in":
+		return exists && containsEqual(strings.Split(value, ","), actual)
+	case "notin":
+		return !exists || !containsEqual(strings.Split(value, ","), actual)
+	case "isnull":
+		isNull := !exists || actual == nil
+		return isNull == (value == "true")
+	case "exists":
+		return exists == (value == "true")
+	case "arraycontains":
+		items, ok := toSlice(actual)
+		return ok && sliceContains(items, value)
+	case "arraycontainsany":
+		items, ok := toSlice(actual)
+		if !ok {
+			return false
+		}
+		for _, v := range strings.Split(value, ",") {
+			if sliceContains(items, v) {
+				return true
+			}
+		}
+		return false
+	case "arraycontainsall":
+		items, ok := toSlice(actual)
+		if !ok {
+			return false
+		}
+		for _, v := range strings.Split(value, ",") {
+			if !sliceContains(items, v) {
+				return false
+			}
+		}
+		return true
+	case "size":
+		items, ok := toSlice(actual)
+		if !ok {
+			return false
+		}
+		n, err := strconv.Atoi(value)
+		return err == nil && len(items) == n
+	case "near", "withinbox", "withinpolygon":
+		point, ok := ParseGeoPoint(actual)
+		return ok && matchGeo(point, op, value)
+	}
+
+	return false
+}
+
+// lookupField resolves a dotted field path against the document data,
+// falling back to the document's own metadata fields.
+func lookupField(doc Document, field string) (interface{}, bool) {
+	var current interface{} = doc.Data
+	found := true
+	for _, part := range strings.Split(field, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			found = false
+			break
+		}
+		current, ok = m[part]
+		if !ok {
+			found = false
+			break
+		}
+	}
+	if found {
+		return current, true
+	}
+
+	switch field {
+	case "id":
+		return doc.ID, doc.ID != ""
+	case "collection":
+		return doc.Collection, doc.Collection != ""
+	case "created_at", "createdAt":
+		return doc.CreatedAt, !doc.CreatedAt.IsZero()
+	case "updated_at", "updatedAt":
+		return doc.UpdatedAt, !doc.UpdatedAt.IsZero()
+	}
+
+	return nil, false
+}
+
+func stringify(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int8:
+		return float64(val), true
+	case int16:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case uint:
+		return float64(val), true
+	case uint8:
+		return float64(val), true
+	case uint16:
+		return float64(val), true
+	case uint32:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case json.Number:
+		f, err := val.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return nil, false
+	}
+
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+func sliceContains(items []interface{}, value string) bool {
+	for _, item := range items {
+		if valueEquals(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEqual(values []string, actual interface{}) bool {
+	for _, v := range values {
+		if valueEquals(actual, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueEquals compares a decoded document value against the string form
+// used in query parameters.
+func valueEquals(actual interface{}, value string) bool {
+	if actual == nil {
+		return value == "" || value == "null" || value == "<nil>"
+	}
+
+	if f, ok := toFloat(actual); ok {
+		n, err := strconv.ParseFloat(value, 64)
+		return err == nil && f == n
+	}
+
+	switch val := actual.(type) {
+	case bool:
+		b, err := strconv.ParseBool(value)
+		return err == nil && val == b
+	case time.Time:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil && val.Equal(t)
+	}
+
+	return stringify(actual) == value
+}
+
+func compareToString(actual interface{}, value string) (int, bool) {
+	if f, ok := toFloat(actual); ok {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false
+		}
+		return compareFloats(f, n), true
+	}
+
+	if t, ok := toTime(actual); ok {
+		if other, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t.Compare(other), true
+		}
+	}
+
+	return strings.Compare(stringify(actual), value), true
+}
+
+// compareFieldValues orders two document values for sorting. Missing values
+// sort before present ones.
+func compareFieldValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return compareFloats(fa, fb)
+		}
+	}
+
+	if ta, ok := toTime(a); ok {
+		if tb, ok := toTime(b); ok {
+			return ta.Compare(tb)
+		}
+	}
+
+	if ba, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ba == bb:
+				return 0
+			case !ba:
+				return -1
+			default:
+				return 1
+			}
+		}
+	}
+
+	return strings.Compare(stringify(a), stringify(b))
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, val)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
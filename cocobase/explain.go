@@ -0,0 +1,297 @@
+package cocobase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var operatorDescriptions = map[string]string{
+	"":                 "=",
+	"ne":               "!=",
+	"gt":               ">",
+	"gte":              ">=",
+	"lt":               "<",
+	"lte":              "<=",
+	"contains":         "contains (case-insensitive)",
+	"startswith":       "starts with (case-insensitive)",
+	"endswith":         "ends with (case-insensitive)",
+	"containscs":       "contains (case-sensitive)",
+	"startswithcs":     "starts with (case-sensitive)",
+	"endswithcs":       "ends with (case-sensitive)",
+	"regex":            "matches regex",
+	"in":               "in",
+	"notin":            "not in",
+	"arraycontains":    "array contains",
+	"arraycontainsany": "array contains any of",
+	"arraycontainsall": "array contains all of",
+	"size":             "has size",
+	"near":             "is within (lat,lng,meters)",
+	"withinbox":        "is within box (swLat,swLng,neLat,neLng)",
+	"withinpolygon":    "is within polygon",
+}
+
+// ============================================
+// EXPLAIN / DEBUG
+// ============================================
+
+// Explain returns a human-readable description of the query: every AND
+// condition, OR group, pagination and sort, the server parameters each one
+// maps to, and warnings for anything that looks malformed.
+func (qb *QueryBuilder) Explain() string {
+	var b strings.Builder
+	var warnings []string
+
+	b.WriteString("AND conditions:\n")
+	if len(qb.filters) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	keys := make([]string, 0, len(qb.filters))
+	for key := range qb.filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		desc, warning := describeFilter(key, qb.filters[key])
+		fmt.Fprintf(&b, "  %s  ->  %s\n", desc, encodeParam(key, qb.filters[key]))
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	groups := make([]string, 0, len(qb.orFilters))
+	for group := range qb.orFilters {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		if group == "" {
+			b.WriteString("OR group (default), at least one must match:\n")
+		} else {
+			fmt.Fprintf(&b, "OR group %q, at least one must match:\n", group)
+		}
+		for _, filter := range qb.orFilters[group] {
+			key, value, ok := parseOrFilter(filter)
+			if !ok {
+				fmt.Fprintf(&b, "  (unparseable condition %q)\n", filter)
+				warnings = append(warnings, fmt.Sprintf("OR condition %q could not be parsed", filter))
+				continue
+			}
+			desc, warning := describeFilter(key, value)
+			prefix := filter[:strings.Index(filter, "]")+1]
+			fmt.Fprintf(&b, "  %s  ->  %s\n", desc, encodeParam(prefix+key, value))
+			if warning != "" {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	if len(qb.orFilters) > 1 {
+		b.WriteString("All OR groups must be satisfied together with the AND conditions\n")
+	}
+
+	b.WriteString("Pagination: ")
+	switch {
+	case qb.limit > 0 && qb.offset > 0:
+		fmt.Fprintf(&b, "limit %d, offset %d  ->  limit=%d&offset=%d\n", qb.limit, qb.offset, qb.limit, qb.offset)
+	case qb.limit > 0:
+		fmt.Fprintf(&b, "limit %d  ->  limit=%d\n", qb.limit, qb.limit)
+	case qb.offset > 0:
+		fmt.Fprintf(&b, "offset %d, server default limit  ->  offset=%d\n", qb.offset, qb.offset)
+	default:
+		b.WriteString("server defaults\n")
+	}
+	if qb.limit < 0 {
+		warnings = append(warnings, fmt.Sprintf("limit %d is negative and will be ignored", qb.limit))
+	}
+	if qb.offset < 0 {
+		warnings = append(warnings, fmt.Sprintf("offset %d is negative and will be ignored", qb.offset))
+	}
+
+	b.WriteString("Sort: ")
+	switch {
+	case qb.sort != "" && qb.sortOrigin != nil:
+		fmt.Fprintf(&b, "%s by distance from %s, %s  ->  sort=%s&order=%s&sort_origin=%s\n",
+			qb.sort, qb.sortOrigin, orderName(qb.order), qb.sort, qb.order, qb.sortOrigin)
+	case qb.sort != "":
+		fmt.Fprintf(&b, "%s %s  ->  sort=%s&order=%s\n", qb.sort, orderName(qb.order), qb.sort, qb.order)
+	default:
+		b.WriteString("server default\n")
+		if qb.order != "" {
+			warnings = append(warnings, fmt.Sprintf("order %q is set but no sort field was given, so it is not sent", qb.order))
+		}
+	}
+
+	if len(warnings) > 0 {
+		b.WriteString("Warnings:\n")
+		for _, w := range warnings {
+			fmt.Fprintf(&b, "  - %s\n", w)
+		}
+	}
+
+	return b.String()
+}
+
+func describeFilter(key, value string) (desc, warning string) {
+	field, op := splitFilterKey(key)
+	subject := field
+	if strings.Contains(field, "__or__") {
+		subject = "any of (" + strings.Join(strings.Split(field, "__or__"), ", ") + ")"
+	}
+
+	switch op {
+	case "isnull":
+		if value == "true" {
+			return subject + " is null or missing", ""
+		}
+		return subject + " is not null", ""
+	case "exists":
+		if value == "true" {
+			return subject + " exists", ""
+		}
+		return subject + " does not exist", ""
+	case "regex":
+		if _, err := regexp.Compile(value); err != nil {
+			warning = fmt.Sprintf("%s: invalid regular expression: %v", field, err)
+		}
+	case "in", "notin", "arraycontainsany", "arraycontainsall":
+		if value == "" {
+			warning = fmt.Sprintf("%s: %s list is empty", field, op)
+		}
+	case "size":
+		if _, err := strconv.Atoi(value); err != nil {
+			warning = fmt.Sprintf("%s: size %q is not an integer", field, value)
+		}
+	case "near", "withinbox", "withinpolygon":
+		if !validGeoValue(op, value) {
+			warning = fmt.Sprintf("%s: malformed %s value %q", field, op, value)
+		}
+	}
+
+	if field == "" {
+		warning = fmt.Sprintf("filter %q has an empty field name", key)
+	}
+
+	return fmt.Sprintf("%s %s %q", subject, operatorDescriptions[op], value), warning
+}
+
+func validGeoValue(op, value string) bool {
+	switch op {
+	case "near":
+		nums, ok := parseFloats(strings.Split(value, ","))
+		return ok && len(nums) == 3
+	case "withinbox":
+		nums, ok := parseFloats(strings.Split(value, ","))
+		return ok && len(nums) == 4
+	default:
+		pairs := strings.Split(value, ";")
+		if len(pairs) < 3 {
+			return false
+		}
+		for _, pair := range pairs {
+			nums, ok := parseFloats(strings.Split(pair, ","))
+			if !ok || len(nums) != 2 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func encodeParam(key, value string) string {
+	return url.Values{key: {value}}.Encode()
+}
+
+func orderName(order string) string {
+	if order == "desc" {
+		return "descending"
+	}
+	return "ascending"
+}
+
+// ExplainParam is the query parameter that asks the server for its query
+// plan instead of results
+const ExplainParam = "explain"
+
+// QueryPlan describes how a query is interpreted
+type QueryPlan struct {
+	// Description is the client-side explanation from QueryBuilder.Explain
+	Description string
+	// Query is the encoded query string sent to the server
+	Query string
+	// ServerSupported is true when the server returned a plan
+	ServerSupported bool
+	// Server is the plan returned by the server, if any
+	Server map[string]interface{}
+}
+
+// ExplainQuery asks the server how it would execute query on collection.
+// Servers that do not support explain still get a QueryPlan with the
+// client-side description and ServerSupported set to false.
+//
+// Explain is a separate method rather than a CallOption on ListDocuments
+// because the server answers with a plan instead of documents, which a
+// []Document result has no room for.
+func (c *Client) ExplainQuery(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) (*QueryPlan, error) {
+	if query == nil {
+		query = NewQuery()
+	}
+
+	plan := &QueryPlan{
+		Description: query.Explain(),
+		Query:       query.Build(),
+	}
+
+	params := url.Values{ExplainParam: {"true"}}.Encode()
+	if plan.Query != "" {
+		params = plan.Query + "&" + params
+	}
+	path := fmt.Sprintf("/collections/%s/documents?%s", collection, params)
+
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsExplain, Collection: collection}, http.MethodGet, path, nil, true, opts...)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && explainUnsupported(apiErr.StatusCode) {
+			return plan, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return plan, nil
+	}
+
+	var server map[string]interface{}
+	if err := c.unmarshal(body, &server); err != nil {
+		// A server without explain support ignores the parameter and returns
+		// the documents as a list
+		var documents []interface{}
+		if c.unmarshal(body, &documents) == nil {
+			return plan, nil
+		}
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	plan.Server = server
+	plan.ServerSupported = true
+
+	return plan, nil
+}
+
+func explainUnsupported(status int) bool {
+	return status == http.StatusBadRequest ||
+		status == http.StatusNotFound ||
+		status == http.StatusNotImplemented
+}
@@ -0,0 +1,266 @@
+package cocobase
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusMeters is the mean Earth radius used for distance calculations
+const EarthRadiusMeters = 6371008.8
+
+// GeoPoint is a latitude/longitude pair. It is stored in Document.Data as
+// {"lat": <float>, "lng": <float>}.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// NewGeoPoint creates a GeoPoint
+func NewGeoPoint(lat, lng float64) GeoPoint {
+	return GeoPoint{Lat: lat, Lng: lng}
+}
+
+// ToData returns the point in the form it is stored in Document.Data
+func (p GeoPoint) ToData() map[string]interface{} {
+	return map[string]interface{}{
+		"lat": p.Lat,
+		"lng": p.Lng,
+	}
+}
+
+// String returns the point as "lat,lng"
+func (p GeoPoint) String() string {
+	return formatFloat(p.Lat) + "," + formatFloat(p.Lng)
+}
+
+// DistanceTo returns the great-circle distance to q in meters (haversine)
+func (p GeoPoint) DistanceTo(q GeoPoint) float64 {
+	lat1 := p.Lat * math.Pi / 180
+	lat2 := q.Lat * math.Pi / 180
+	dLat := (q.Lat - p.Lat) * math.Pi / 180
+	dLng := (q.Lng - p.Lng) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// ParseGeoPoint extracts a GeoPoint from a document value. It accepts the
+// {"lat", "lng"} form written by GeoPoint, {"latitude", "longitude"} and
+// GeoJSON points ({"type": "Point", "coordinates": [lng, lat]}).
+func ParseGeoPoint(v interface{}) (GeoPoint, bool) {
+	switch val := v.(type) {
+	case GeoPoint:
+		return val, true
+	case *GeoPoint:
+		if val == nil {
+			return GeoPoint{}, false
+		}
+		return *val, true
+	case map[string]interface{}:
+		if lat, ok := toFloat(val["lat"]); ok {
+			if lng, ok := toFloat(val["lng"]); ok {
+				return GeoPoint{Lat: lat, Lng: lng}, true
+			}
+		}
+		if lat, ok := toFloat(val["latitude"]); ok {
+			if lng, ok := toFloat(val["longitude"]); ok {
+				return GeoPoint{Lat: lat, Lng: lng}, true
+			}
+		}
+		if coords, ok := toSlice(val["coordinates"]); ok && len(coords) == 2 {
+			lng, ok1 := toFloat(coords[0])
+			lat, ok2 := toFloat(coords[1])
+			if ok1 && ok2 {
+				return GeoPoint{Lat: lat, Lng: lng}, true
+			}
+		}
+	}
+
+	return GeoPoint{}, false
+}
+
+// GeoPoint reads field from the document as a GeoPoint
+func (d *Document) GeoPoint(field string) (GeoPoint, bool) {
+	value, ok := lookupField(*d, field)
+	if !ok {
+		return GeoPoint{}, false
+	}
+	return ParseGeoPoint(value)
+}
+
+// ============================================
+// GEOSPATIAL OPERATORS
+// ============================================
+
+// Near adds a filter for points within radius meters of (lat, lng)
+func (qb *QueryBuilder) Near(field string, lat, lng, radius float64) *QueryBuilder {
+	key := fmt.Sprintf("%s_near", field)
+	qb.filters[key] = fmt.Sprintf("%s,%s,%s", formatFloat(lat), formatFloat(lng), formatFloat(radius))
+	return qb
+}
+
+// WithinBox adds a filter for points inside the box spanned by its
+// south-west and north-east corners
+func (qb *QueryBuilder) WithinBox(field string, southWest, northEast GeoPoint) *QueryBuilder {
+	key := fmt.Sprintf("%s_withinbox", field)
+	qb.filters[key] = southWest.String() + "," + northEast.String()
+	return qb
+}
+
+// WithinPolygon adds a filter for points inside the polygon with the given vertices
+func (qb *QueryBuilder) WithinPolygon(field string, vertices ...GeoPoint) *QueryBuilder {
+	key := fmt.Sprintf("%s_withinpolygon", field)
+	points := make([]string, len(vertices))
+	for i, v := range vertices {
+		points[i] = v.String()
+	}
+	qb.filters[key] = strings.Join(points, ";")
+	return qb
+}
+
+// OrderByDistance sorts results by distance from (lat, lng), nearest first
+func (qb *QueryBuilder) OrderByDistance(field string, lat, lng float64) *QueryBuilder {
+	origin := NewGeoPoint(lat, lng)
+	qb.sort = field
+	qb.order = "asc"
+	qb.sortOrigin = &origin
+	return qb
+}
+
+func (qb *QueryBuilder) hasGeo() bool {
+	if qb.sortOrigin != nil {
+		return true
+	}
+	for key := range qb.filters {
+		if _, op := splitFilterKey(key); isGeoOperator(op) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutGeo returns a copy of the query the server can evaluate: geo
+// filters, distance sorting and pagination are removed because they have to
+// be applied locally afterwards.
+func (qb *QueryBuilder) withoutGeo() *QueryBuilder {
+	c := qb.clone()
+	for key := range c.filters {
+		if _, op := splitFilterKey(key); isGeoOperator(op) {
+			delete(c.filters, key)
+		}
+	}
+	c.limit = 0
+	c.offset = 0
+	if c.sortOrigin != nil {
+		c.sort = ""
+		c.order = ""
+		c.sortOrigin = nil
+	}
+	return c
+}
+
+func isGeoOperator(op string) bool {
+	return op == "near" || op == "withinbox" || op == "withinpolygon"
+}
+
+func matchGeo(point GeoPoint, op, value string) bool {
+	switch op {
+	case "near":
+		nums, ok := parseFloats(strings.Split(value, ","))
+		if !ok || len(nums) != 3 {
+			return false
+		}
+		return point.DistanceTo(NewGeoPoint(nums[0], nums[1])) <= nums[2]
+	case "withinbox":
+		nums, ok := parseFloats(strings.Split(value, ","))
+		if !ok || len(nums) != 4 {
+			return false
+		}
+		if point.Lat < nums[0] || point.Lat > nums[2] {
+			return false
+		}
+		// A box whose west edge is east of its east edge crosses the antimeridian
+		if nums[1] <= nums[3] {
+			return point.Lng >= nums[1] && point.Lng <= nums[3]
+		}
+		return point.Lng >= nums[1] || point.Lng <= nums[3]
+	case "withinpolygon":
+		var vertices []GeoPoint
+		for _, pair := range strings.Split(value, ";") {
+			nums, ok := parseFloats(strings.Split(pair, ","))
+			if !ok || len(nums) != 2 {
+				return false
+			}
+			vertices = append(vertices, NewGeoPoint(nums[0], nums[1]))
+		}
+		return pointInPolygon(point, vertices)
+	}
+	return false
+}
+
+// pointInPolygon uses ray casting on a planar projection, which is accurate
+// enough for polygons that do not span large parts of the globe.
+func pointInPolygon(p GeoPoint, vertices []GeoPoint) bool {
+	if len(vertices) < 3 {
+		return false
+	}
+
+	inside := false
+	j := len(vertices) - 1
+	for i := range vertices {
+		vi, vj := vertices[i], vertices[j]
+		if (vi.Lat > p.Lat) != (vj.Lat > p.Lat) &&
+			p.Lng < (vj.Lng-vi.Lng)*(p.Lat-vi.Lat)/(vj.Lat-vi.Lat)+vi.Lng {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
+
+// sortByDistance orders docs by the distance of field from origin. Documents
+// without a usable point sort last.
+func sortByDistance(docs []Document, field string, origin GeoPoint, desc bool) {
+	distances := make(map[string]float64, len(docs))
+	distance := func(doc *Document) float64 {
+		if d, ok := distances[doc.ID]; ok && doc.ID != "" {
+			return d
+		}
+		d := math.Inf(1)
+		if point, ok := doc.GeoPoint(field); ok {
+			d = point.DistanceTo(origin)
+		}
+		if doc.ID != "" {
+			distances[doc.ID] = d
+		}
+		return d
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		di, dj := distance(&docs[i]), distance(&docs[j])
+		if desc && !math.IsInf(di, 1) && !math.IsInf(dj, 1) {
+			return di > dj
+		}
+		return di < dj
+	})
+}
+
+func parseFloats(parts []string) ([]float64, bool) {
+	nums := make([]float64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,159 @@
+package cocobase
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute key fragments whose values are never logged
+var sensitiveKeys = []string{
+	"password",
+	"token",
+	"secret",
+	"api_key",
+	"apikey",
+	"api-key",
+	"authorization",
+	"cookie",
+}
+
+// newLogger wraps the configured logger so that sensitive attributes are
+// redacted. A nil logger discards everything.
+func newLogger(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(discardHandler{})
+	}
+	return slog.New(&redactHandler{next: logger.Handler()})
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// redactHandler replaces the values of sensitive attributes and bearer
+// tokens before passing records on
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	clean := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		clean.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = redactAttr(a)
+	}
+	return &redactHandler{next: h.next.WithAttrs(clean)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		clean := make([]any, len(group))
+		for i, ga := range group {
+			clean[i] = redactAttr(ga)
+		}
+		return slog.Group(a.Key, clean...)
+	case slog.KindString:
+		return slog.String(a.Key, redactString(v.String()))
+	}
+
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString masks bearer tokens embedded in free-form text such as
+// error messages
+func redactString(s string) string {
+	idx := strings.Index(strings.ToLower(s), "bearer ")
+	if idx < 0 {
+		return s
+	}
+
+	start := idx + len("bearer ")
+	end := start
+	for end < len(s) && s[end] != ' ' && s[end] != '"' && s[end] != '\n' {
+		end++
+	}
+	return s[:start] + redacted + redactString(s[end:])
+}
+
+// logRequest is the innermost middleware: it logs each HTTP round trip as
+// it goes over the wire
+func (c *Client) logRequest(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request, op Operation) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next(req, op)
+
+		attrs := []slog.Attr{
+			slog.String("operation", op.Name),
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("attempt", op.Attempt),
+		}
+		if op.Collection != "" {
+			attrs = append(attrs, slog.String("collection", op.Collection))
+		}
+
+		ctx := req.Context()
+		switch {
+		case err != nil:
+			attrs = append(attrs, slog.String("error", err.Error()))
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "http request failed", attrs...)
+		case resp.StatusCode >= 500:
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "http request", attrs...)
+		default:
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			c.logger.LogAttrs(ctx, slog.LevelDebug, "http request", attrs...)
+		}
+
+		return resp, err
+	}
+}
+
+// logStorageError reports a failed Storage operation without failing the call
+func (c *Client) logStorageError(op, key string, err error) {
+	if err != nil {
+		c.logger.Warn("storage operation failed", "op", op, "key", key, "error", err.Error())
+	}
+}
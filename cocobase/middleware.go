@@ -0,0 +1,70 @@
+package cocobase
+
+import "net/http"
+
+// Operation names passed to middleware
+const (
+	OpDocumentsGet     = "documents.get"
+	OpDocumentsCreate  = "documents.create"
+	OpDocumentsUpdate  = "documents.update"
+	OpDocumentsDelete  = "documents.delete"
+	OpDocumentsList    = "documents.list"
+	OpDocumentsQuery   = "documents.query"
+	OpDocumentsStream  = "documents.stream"
+	OpDocumentsSearch  = "documents.search"
+	OpDocumentsExplain = "documents.explain"
+	OpAuthLogin        = "auth.login"
+	OpAuthRegister     = "auth.register"
+	OpAuthGetUser      = "auth.get_user"
+	OpAuthUpdateUser   = "auth.update_user"
+	OpAuthRefresh      = "auth.refresh"
+
+	OpAuthRequestPasswordReset = "auth.request_password_reset"
+	OpAuthConfirmPasswordReset = "auth.confirm_password_reset"
+	OpAuthChangePassword       = "auth.change_password"
+	OpAuthSendVerification     = "auth.send_verification_email"
+	OpAuthVerifyEmail          = "auth.verify_email"
+	OpAuthOAuth                = "auth.oauth"
+	OpAuthVerifyMFA            = "auth.verify_mfa"
+	OpAuthEnrollTOTP           = "auth.enroll_totp"
+	OpAuthConfirmTOTP          = "auth.confirm_totp"
+	OpAuthDisableMFA           = "auth.disable_mfa"
+	OpAuthRecoveryCodes        = "auth.recovery_codes"
+	OpAuthSendMagicLink        = "auth.send_magic_link"
+	OpAuthVerifyMagicLink      = "auth.verify_magic_link"
+	OpAuthSendLoginCode        = "auth.send_login_code"
+	OpAuthVerifyLoginCode      = "auth.verify_login_code"
+)
+
+// Operation describes the logical API call an HTTP request belongs to
+type Operation struct {
+	// Name is one of the Op* constants, e.g. "documents.list"
+	Name string
+	// Collection is the target collection, if any
+	Collection string
+	// DocumentID is the target document, if any
+	DocumentID string
+	// Attempt is 1 for the first try and increases with each retry
+	Attempt int
+}
+
+// RoundTripFunc sends a request and returns the raw response. Responses
+// with error status codes are returned as-is; the client converts them to
+// *APIError after the middleware chain has run.
+type RoundTripFunc func(req *http.Request, op Operation) (*http.Response, error)
+
+// Middleware wraps every request made by the client, for tracing, metrics,
+// auditing, header injection or request rewriting
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chainMiddleware composes middleware around final so that the first
+// middleware is the outermost
+func chainMiddleware(final RoundTripFunc, middleware []Middleware) RoundTripFunc {
+	next := final
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			next = middleware[i](next)
+		}
+	}
+	return next
+}
@@ -0,0 +1,255 @@
+package cocobase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Authorization endpoints of common providers
+const (
+	GoogleAuthURL = "https://accounts.google.com/o/oauth2/v2/auth"
+	GitHubAuthURL = "https://github.com/login/oauth/authorize"
+)
+
+const DefaultOAuthCallbackPath = "/callback"
+
+// ErrOAuthStateMismatch is returned when the callback's state parameter does
+// not match the flow's, which may indicate a forged redirect
+var ErrOAuthStateMismatch = errors.New("cocobase: oauth state mismatch")
+
+// OAuthError is an error reported by the provider on the redirect, such as
+// the user denying access
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth error: %s: %s", e.Code, e.Description)
+	}
+	return "oauth error: " + e.Code
+}
+
+// OAuthConfig describes a provider to sign in with
+type OAuthConfig struct {
+	// Provider is the provider's name in Cocobase, e.g. "google" or "github"
+	Provider string
+	// AuthURL is the provider's authorization endpoint, e.g. GoogleAuthURL
+	AuthURL  string
+	ClientID string
+	Scopes   []string
+	// Params are extra authorization parameters, e.g. "prompt": "consent"
+	Params map[string]string
+
+	// ListenAddr is the loopback address LoginWithOAuth listens on for the
+	// redirect (default "127.0.0.1:0", a random port). Providers that
+	// require an exact redirect URL need a fixed port.
+	ListenAddr string
+	// CallbackPath is the redirect path (default DefaultOAuthCallbackPath)
+	CallbackPath string
+	// OpenURL shows the authorization URL to the user, e.g. by opening a
+	// browser or printing it. Required by LoginWithOAuth.
+	OpenURL func(authURL string) error
+}
+
+// OAuthFlow is one authorization attempt. Keep it until the redirect arrives
+// to exchange the code.
+type OAuthFlow struct {
+	Provider    string
+	RedirectURL string
+	State       string
+	// CodeVerifier is the PKCE secret; it never leaves the client until the
+	// code exchange
+	CodeVerifier string
+	// AuthURL is where to send the user
+	AuthURL string
+}
+
+// NewOAuthFlow builds the provider authorization URL for redirectURL, with a
+// random state and a PKCE (S256) code challenge. Use it directly when the
+// redirect is handled by your own server; CLIs and desktop apps can use
+// LoginWithOAuth instead.
+func (c *Client) NewOAuthFlow(config OAuthConfig, redirectURL string) (*OAuthFlow, error) {
+	if config.Provider == "" || config.AuthURL == "" || config.ClientID == "" {
+		return nil, fmt.Errorf("%w: oauth Provider, AuthURL and ClientID are required", ErrInvalidConfig)
+	}
+
+	authURL, err := url.Parse(config.AuthURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid oauth AuthURL: %v", ErrInvalidConfig, err)
+	}
+
+	state, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &OAuthFlow{
+		Provider:     config.Provider,
+		RedirectURL:  redirectURL,
+		State:        state,
+		CodeVerifier: verifier,
+	}
+	challenge := sha256.Sum256([]byte(flow.CodeVerifier))
+
+	params := authURL.Query()
+	for k, v := range config.Params {
+		params.Set(k, v)
+	}
+	params.Set("response_type", "code")
+	params.Set("client_id", config.ClientID)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("state", flow.State)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+	if len(config.Scopes) > 0 {
+		params.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	authURL.RawQuery = params.Encode()
+	flow.AuthURL = authURL.String()
+
+	return flow, nil
+}
+
+// CompleteOAuth checks the redirect's query parameters against the flow and
+// exchanges the code, signing the client in like Login
+func (c *Client) CompleteOAuth(ctx context.Context, flow *OAuthFlow, query url.Values, opts ...CallOption) error {
+	if code := query.Get("error"); code != "" {
+		return &OAuthError{Code: code, Description: query.Get("error_description")}
+	}
+	if query.Get("state") != flow.State {
+		return ErrOAuthStateMismatch
+	}
+	code := query.Get("code")
+	if code == "" {
+		return fmt.Errorf("oauth redirect has no code")
+	}
+
+	return c.ExchangeOAuthCode(ctx, flow, code, opts...)
+}
+
+// ExchangeOAuthCode exchanges an authorization code through Cocobase and
+// signs the client in like Login. If the account requires MFA, it fails with
+// an *MFAChallenge to complete with VerifyMFA.
+func (c *Client) ExchangeOAuthCode(ctx context.Context, flow *OAuthFlow, code string, opts ...CallOption) error {
+	session, err := c.ExchangeOAuthCodeSession(ctx, flow, code, opts...)
+	if err != nil {
+		c.holdMFAChallenge(err)
+		return err
+	}
+
+	if err := c.signIn(session); err != nil {
+		return err
+	}
+
+	c.logger.Info("signed in", "user_id", session.User.ID, "provider", flow.Provider)
+
+	return nil
+}
+
+// ExchangeOAuthCodeSession exchanges an authorization code and returns a
+// session, leaving the client's own auth state untouched. If the account
+// requires MFA, the error is an *MFAChallenge to pass to VerifyMFASession.
+func (c *Client) ExchangeOAuthCodeSession(ctx context.Context, flow *OAuthFlow, code string, opts ...CallOption) (*Session, error) {
+	body := map[string]string{
+		"code":          code,
+		"code_verifier": flow.CodeVerifier,
+		"redirect_uri":  flow.RedirectURL,
+	}
+	path := "/auth-collections/oauth/" + url.PathEscape(flow.Provider)
+
+	return c.authenticate(ctx, Operation{Name: OpAuthOAuth}, path, body, opts)
+}
+
+// LoginWithOAuth signs in through a provider from a CLI or desktop app. It
+// listens on a loopback address for the redirect, passes the authorization
+// URL to config.OpenURL, waits for the user to finish (bounded by ctx), and
+// exchanges the code like ExchangeOAuthCode.
+func (c *Client) LoginWithOAuth(ctx context.Context, config OAuthConfig, opts ...CallOption) error {
+	if config.OpenURL == nil {
+		return fmt.Errorf("%w: oauth OpenURL is required", ErrInvalidConfig)
+	}
+	if config.ListenAddr == "" {
+		config.ListenAddr = "127.0.0.1:0"
+	}
+	if config.CallbackPath == "" {
+		config.CallbackPath = DefaultOAuthCallbackPath
+	}
+
+	listener, err := net.Listen("tcp", config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for oauth redirect: %w", err)
+	}
+
+	redirectURL := "http://" + listener.Addr().String() + config.CallbackPath
+	flow, err := c.NewOAuthFlow(config, redirectURL)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	redirects := make(chan url.Values, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		// Ignore stray requests, e.g. a browser fetching favicon or a
+		// replayed redirect
+		if r.URL.Query().Get("state") != flow.State {
+			http.Error(w, "Unknown sign-in attempt.", http.StatusBadRequest)
+			return
+		}
+		select {
+		case redirects <- r.URL.Query():
+		default:
+		}
+		// The code has not been exchanged yet, so success is not known here
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if code := r.URL.Query().Get("error"); code != "" {
+			fmt.Fprintf(w, "Sign-in failed (%s). Return to the application.", code)
+			return
+		}
+		fmt.Fprint(w, "Return to the application to finish signing in. You can close this window.")
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer func() {
+		// Let the browser receive its page before closing
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := config.OpenURL(flow.AuthURL); err != nil {
+		return fmt.Errorf("failed to open authorization URL: %w", err)
+	}
+
+	select {
+	case query := <-redirects:
+		return c.CompleteOAuth(ctx, flow, query, opts...)
+	case <-ctx.Done():
+		return fmt.Errorf("oauth sign-in not completed: %w", ctx.Err())
+	}
+}
+
+// randomToken returns n random bytes, base64url encoded
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate oauth secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,230 @@
+package cocobase
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv
+const (
+	EnvAPIKey    = "COCOBASE_API_KEY"
+	EnvBaseURL   = "COCOBASE_BASE_URL"
+	EnvTimeout   = "COCOBASE_TIMEOUT"
+	EnvUserAgent = "COCOBASE_USER_AGENT"
+	EnvProxy     = "COCOBASE_PROXY"
+)
+
+// ErrInvalidConfig is wrapped by every configuration error returned from New,
+// NewClientFromEnv and Config.Validate
+var ErrInvalidConfig = errors.New("invalid client configuration")
+
+// Option configures a client created with New or NewClientFromEnv
+type Option func(*Config) error
+
+// New creates a client from options, returning an error for invalid
+// configuration instead of failing on the first request
+func New(opts ...Option) (*Client, error) {
+	var config Config
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return NewClient(config), nil
+}
+
+// NewClientFromEnv creates a client configured from COCOBASE_API_KEY,
+// COCOBASE_BASE_URL, COCOBASE_TIMEOUT (a duration such as "10s", or seconds),
+// COCOBASE_USER_AGENT and COCOBASE_PROXY. Options override the environment.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	return New(append([]Option{fromEnv}, opts...)...)
+}
+
+func fromEnv(config *Config) error {
+	if v := os.Getenv(EnvAPIKey); v != "" {
+		config.APIKey = v
+	}
+	if v := os.Getenv(EnvBaseURL); v != "" {
+		config.BaseURL = v
+	}
+	if v := os.Getenv(EnvUserAgent); v != "" {
+		config.UserAgent = v
+	}
+
+	if v := os.Getenv(EnvTimeout); v != "" {
+		timeout, err := parseTimeout(v)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, EnvTimeout, err)
+		}
+		config.Timeout = timeout
+	}
+
+	if v := os.Getenv(EnvProxy); v != "" {
+		if err := WithProxy(v)(config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Validate reports configuration that would make every request fail
+func (c Config) Validate() error {
+	if c.BaseURL != "" {
+		u, err := url.Parse(c.BaseURL)
+		if err != nil {
+			return fmt.Errorf("%w: base URL %q: %v", ErrInvalidConfig, c.BaseURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("%w: base URL %q must use http or https", ErrInvalidConfig, c.BaseURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("%w: base URL %q has no host", ErrInvalidConfig, c.BaseURL)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("%w: base URL %q must not have a query or fragment", ErrInvalidConfig, c.BaseURL)
+		}
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("%w: timeout %s is negative", ErrInvalidConfig, c.Timeout)
+	}
+
+	if c.HTTPClient != nil && (c.Timeout != 0 || c.Proxy != nil) {
+		return fmt.Errorf("%w: Timeout and Proxy cannot be combined with a custom HTTPClient", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// ============================================
+// OPTIONS
+// ============================================
+
+// WithConfig starts from an existing Config; later options override it
+func WithConfig(config Config) Option {
+	return func(c *Config) error {
+		*c = config
+		return nil
+	}
+}
+
+// WithAPIKey sets the API key
+func WithAPIKey(apiKey string) Option {
+	return func(c *Config) error {
+		c.APIKey = apiKey
+		return nil
+	}
+}
+
+// WithBaseURL sets the Cocobase API URL
+func WithBaseURL(baseURL string) Option {
+	return func(c *Config) error {
+		c.BaseURL = baseURL
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Config) error {
+		c.HTTPClient = client
+		return nil
+	}
+}
+
+// WithStorage sets where the auth token and user are persisted
+func WithStorage(storage Storage) Option {
+	return func(c *Config) error {
+		c.Storage = storage
+		return nil
+	}
+}
+
+// WithTimeout sets the overall timeout for each request
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Config) error {
+		c.Timeout = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Config) error {
+		c.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithHeader adds a header sent with every request. It cannot override the
+// API key, authorization or content type headers.
+func WithHeader(key, value string) Option {
+	return func(c *Config) error {
+		if c.Headers == nil {
+			c.Headers = make(http.Header)
+		}
+		c.Headers.Add(key, value)
+		return nil
+	}
+}
+
+// WithProxy sends requests through the proxy at proxyURL
+func WithProxy(proxyURL string) Option {
+	return func(c *Config) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%w: proxy URL %q is malformed", ErrInvalidConfig, proxyURL)
+		}
+		c.Proxy = u
+		return nil
+	}
+}
+
+// WithLogger sets the structured logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Config) error {
+		c.Logger = logger
+		return nil
+	}
+}
+
+// WithMiddleware appends middleware around every request
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Config) error {
+		c.Middleware = append(c.Middleware, middleware...)
+		return nil
+	}
+}
+
+// newHTTPClient builds the default HTTP client from the timeout and proxy settings
+func newHTTPClient(config Config) *http.Client {
+	client := &http.Client{Timeout: DefaultTimeout}
+	if config.Timeout > 0 {
+		client.Timeout = config.Timeout
+	}
+	if config.Proxy != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(config.Proxy)
+		client.Transport = transport
+	}
+	return client
+}
@@ -0,0 +1,130 @@
+package cocobase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrRateLimited matches the *RateLimitError returned when the server
+// refuses to send another link or code yet
+var ErrRateLimited = errors.New("cocobase: rate limited")
+
+// RateLimitError is returned by passwordless sign-in calls rejected with
+// 429 Too Many Requests
+type RateLimitError struct {
+	// RetryAfter is how long to wait before trying again, or zero if the
+	// server did not say
+	RetryAfter time.Duration
+	Err        *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("cocobase: rate limited, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() []error {
+	return []error{ErrRateLimited, e.Err}
+}
+
+// rateLimitError converts a 429 response into a *RateLimitError
+func rateLimitError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		return err
+	}
+
+	rlErr := &RateLimitError{Err: apiErr}
+	now := time.Now()
+	if d, ok := parseRetryAfter(apiErr.Header.Get(HeaderRetryAfter), now); ok {
+		rlErr.RetryAfter = d
+	} else if reset, ok := parseRateLimitReset(apiErr.Header.Get(HeaderRateLimitReset), now); ok {
+		rlErr.RetryAfter = reset.Sub(now)
+	}
+	return rlErr
+}
+
+// SendMagicLink emails a sign-in link to email. After sign-in, the server
+// redirects to redirectURL with a token to pass to VerifyMagicLink.
+// Requesting links too often fails with a *RateLimitError.
+func (c *Client) SendMagicLink(ctx context.Context, email, redirectURL string, opts ...CallOption) error {
+	body := map[string]string{
+		"email":        email,
+		"redirect_url": redirectURL,
+	}
+	err := c.postAuth(ctx, Operation{Name: OpAuthSendMagicLink}, "/auth-collections/magic-link", body, withoutAuth(opts))
+	return rateLimitError(err)
+}
+
+// VerifyMagicLink signs the client in like Login with the token from a magic
+// link. It fails with ErrTokenExpired or ErrTokenInvalid if the token is
+// rejected, or with an *MFAChallenge if the account requires MFA.
+func (c *Client) VerifyMagicLink(ctx context.Context, token string, opts ...CallOption) error {
+	return c.passwordlessSignIn(c.VerifyMagicLinkSession(ctx, token, opts...))
+}
+
+// VerifyMagicLinkSession verifies a magic link token like VerifyMagicLink
+// but returns a session, leaving the client's own auth state untouched
+func (c *Client) VerifyMagicLinkSession(ctx context.Context, token string, opts ...CallOption) (*Session, error) {
+	body := map[string]string{"token": token}
+	session, err := c.authenticate(ctx, Operation{Name: OpAuthVerifyMagicLink}, "/auth-collections/magic-link/verify", body, opts)
+	return session, passwordlessError(err)
+}
+
+// SendLoginCode emails a one-time sign-in code to email. Requesting codes
+// too often fails with a *RateLimitError.
+func (c *Client) SendLoginCode(ctx context.Context, email string, opts ...CallOption) error {
+	body := map[string]string{"email": email}
+	err := c.postAuth(ctx, Operation{Name: OpAuthSendLoginCode}, "/auth-collections/login-code", body, withoutAuth(opts))
+	return rateLimitError(err)
+}
+
+// VerifyLoginCode signs the client in like Login with a code sent by
+// SendLoginCode. A wrong code fails with ErrTokenInvalid, an old one with
+// ErrTokenExpired, and too many attempts with a *RateLimitError.
+func (c *Client) VerifyLoginCode(ctx context.Context, email, code string, opts ...CallOption) error {
+	return c.passwordlessSignIn(c.VerifyLoginCodeSession(ctx, email, code, opts...))
+}
+
+// VerifyLoginCodeSession verifies a sign-in code like VerifyLoginCode but
+// returns a session, leaving the client's own auth state untouched
+func (c *Client) VerifyLoginCodeSession(ctx context.Context, email, code string, opts ...CallOption) (*Session, error) {
+	body := map[string]string{
+		"email": email,
+		"code":  code,
+	}
+	session, err := c.authenticate(ctx, Operation{Name: OpAuthVerifyLoginCode}, "/auth-collections/login-code/verify", body, opts)
+	return session, passwordlessError(err)
+}
+
+// passwordlessError classifies a rejected code or link; an *MFAChallenge is
+// returned as is
+func passwordlessError(err error) error {
+	if err == nil || errors.Is(err, ErrMFARequired) {
+		return err
+	}
+	if limited := rateLimitError(err); limited != err {
+		return limited
+	}
+	return tokenError(err)
+}
+
+func (c *Client) passwordlessSignIn(session *Session, err error) error {
+	if err != nil {
+		c.holdMFAChallenge(err)
+		return err
+	}
+
+	if err := c.signIn(session); err != nil {
+		return err
+	}
+
+	c.logger.Info("signed in", "user_id", session.User.ID, "passwordless", true)
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package cocobase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const DefaultIdleTimeout = 30 * time.Minute
+
+// ErrNoTenant is returned by ClientPool.FromContext when the context carries
+// no project key
+var ErrNoTenant = errors.New("no cocobase project in context")
+
+// PoolConfig configures a ClientPool
+type PoolConfig struct {
+	// Base is the configuration every client starts from; its APIKey is
+	// replaced by the project key. Storage, if set, is shared and namespaced
+	// per project.
+	Base Config
+
+	// Configure optionally adjusts a project's configuration before its
+	// client is created, e.g. to set a per-project base URL
+	Configure func(apiKey string, config *Config)
+
+	// IdleTimeout evicts clients that have not been used for this long
+	// (default DefaultIdleTimeout, negative never evicts)
+	IdleTimeout time.Duration
+}
+
+// ClientPool lazily creates and caches one Client per project API key. The
+// clients share one HTTP transport but keep their own token, user and
+// storage namespace.
+type ClientPool struct {
+	mu         sync.Mutex
+	config     PoolConfig
+	httpClient *http.Client
+	clients    map[string]*pooledClient
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+type pooledClient struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// NewClientPool creates an empty pool
+func NewClientPool(config PoolConfig) *ClientPool {
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+
+	httpClient := config.Base.HTTPClient
+	if httpClient == nil {
+		httpClient = newHTTPClient(config.Base)
+		if httpClient.Transport == nil {
+			httpClient.Transport = http.DefaultTransport.(*http.Transport).Clone()
+		}
+	}
+
+	return &ClientPool{
+		config:     config,
+		httpClient: httpClient,
+		clients:    make(map[string]*pooledClient),
+		now:        time.Now,
+	}
+}
+
+// Get returns the client for apiKey, creating it on first use
+func (p *ClientPool) Get(apiKey string) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.sweep(now)
+
+	if pc, ok := p.clients[apiKey]; ok {
+		pc.lastUsed = now
+		return pc.client
+	}
+
+	config := p.config.Base
+	config.APIKey = apiKey
+	config.HTTPClient = p.httpClient
+	config.Timeout = 0
+	config.Proxy = nil
+	if config.Storage != nil {
+		config.Storage = &namespacedStorage{storage: config.Storage, prefix: storageNamespace(apiKey)}
+	}
+	if p.config.Configure != nil {
+		p.config.Configure(apiKey, &config)
+	}
+
+	client := NewClient(config)
+	p.clients[apiKey] = &pooledClient{client: client, lastUsed: now}
+	return client
+}
+
+// Remove drops the client for apiKey from the pool
+func (p *ClientPool) Remove(apiKey string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, apiKey)
+}
+
+// Len returns the number of cached clients
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// EvictIdle drops clients unused for longer than the idle timeout. It also
+// runs periodically from Get.
+func (p *ClientPool) EvictIdle() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastSweep = time.Time{}
+	p.sweep(p.now())
+}
+
+func (p *ClientPool) sweep(now time.Time) {
+	timeout := p.config.IdleTimeout
+	if timeout < 0 || now.Sub(p.lastSweep) < timeout/2 {
+		return
+	}
+	p.lastSweep = now
+
+	for key, pc := range p.clients {
+		if now.Sub(pc.lastUsed) > timeout {
+			delete(p.clients, key)
+		}
+	}
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context carrying the project API key for
+// ClientPool.FromContext
+func WithTenant(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, apiKey)
+}
+
+// TenantFromContext returns the project API key set with WithTenant
+func TenantFromContext(ctx context.Context) (string, bool) {
+	apiKey, ok := ctx.Value(tenantContextKey{}).(string)
+	return apiKey, ok && apiKey != ""
+}
+
+// FromContext returns the client for the project set with WithTenant
+func (p *ClientPool) FromContext(ctx context.Context) (*Client, error) {
+	apiKey, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, ErrNoTenant
+	}
+	return p.Get(apiKey), nil
+}
+
+// storageNamespace derives a key prefix from the API key without storing
+// the key itself
+func storageNamespace(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "project-" + hex.EncodeToString(sum[:8]) + ":"
+}
+
+// namespacedStorage prefixes every key so tenants sharing a Storage do not
+// see each other's tokens
+type namespacedStorage struct {
+	storage Storage
+	prefix  string
+}
+
+func (s *namespacedStorage) Get(key string) (string, error) {
+	return s.storage.Get(s.prefix + key)
+}
+
+func (s *namespacedStorage) Set(key, value string) error {
+	return s.storage.Set(s.prefix+key, value)
+}
+
+func (s *namespacedStorage) Delete(key string) error {
+	return s.storage.Delete(s.prefix + key)
+}
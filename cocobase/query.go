@@ -8,12 +8,14 @@ import (
 
 // QueryBuilder provides a fluent, intuitive interface for building queries
 type QueryBuilder struct {
-	filters   map[string]string
-	orFilters map[string][]string
-	limit     int
-	offset    int
-	sort      string
-	order     string
+	filters    map[string]string
+	orFilters  map[string][]string
+	limit      int
+	offset     int
+	sort       string
+	order      string
+	sortOrigin *GeoPoint
+	params     map[string]Placeholder
 }
 
 // NewQuery creates a new QueryBuilder
@@ -21,6 +23,7 @@ func NewQuery() *QueryBuilder {
 	return &QueryBuilder{
 		filters:   make(map[string]string),
 		orFilters: make(map[string][]string),
+		params:    make(map[string]Placeholder),
 	}
 }
 
@@ -30,7 +33,7 @@ func NewQuery() *QueryBuilder {
 
 // Where adds an equality filter (field = value)
 func (qb *QueryBuilder) Where(field string, value interface{}) *QueryBuilder {
-	qb.filters[field] = fmt.Sprintf("%v", value)
+	qb.filters[field] = qb.formatValue(value)
 	return qb
 }
 
@@ -42,35 +45,35 @@ func (qb *QueryBuilder) Equals(field string, value interface{}) *QueryBuilder {
 // NotEquals adds a not-equals filter (field != value)
 func (qb *QueryBuilder) NotEquals(field string, value interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_ne", field)
-	qb.filters[key] = fmt.Sprintf("%v", value)
+	qb.filters[key] = qb.formatValue(value)
 	return qb
 }
 
 // GreaterThan adds a greater-than filter (field > value)
 func (qb *QueryBuilder) GreaterThan(field string, value interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_gt", field)
-	qb.filters[key] = fmt.Sprintf("%v", value)
+	qb.filters[key] = qb.formatValue(value)
 	return qb
 }
 
 // GreaterThanOrEqual adds a gte filter (field >= value)
 func (qb *QueryBuilder) GreaterThanOrEqual(field string, value interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_gte", field)
-	qb.filters[key] = fmt.Sprintf("%v", value)
+	qb.filters[key] = qb.formatValue(value)
 	return qb
 }
 
 // LessThan adds a less-than filter (field < value)
 func (qb *QueryBuilder) LessThan(field string, value interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_lt", field)
-	qb.filters[key] = fmt.Sprintf("%v", value)
+	qb.filters[key] = qb.formatValue(value)
 	return qb
 }
 
 // LessThanOrEqual adds a lte filter (field <= value)
 func (qb *QueryBuilder) LessThanOrEqual(field string, value interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_lte", field)
-	qb.filters[key] = fmt.Sprintf("%v", value)
+	qb.filters[key] = qb.formatValue(value)
 	return qb
 }
 
@@ -106,6 +109,34 @@ func (qb *QueryBuilder) EndsWith(field, suffix string) *QueryBuilder {
 	return qb
 }
 
+// ContainsCaseSensitive adds a case-sensitive substring search filter
+func (qb *QueryBuilder) ContainsCaseSensitive(field, substring string) *QueryBuilder {
+	key := fmt.Sprintf("%s_containscs", field)
+	qb.filters[key] = substring
+	return qb
+}
+
+// StartsWithCaseSensitive adds a case-sensitive prefix filter
+func (qb *QueryBuilder) StartsWithCaseSensitive(field, prefix string) *QueryBuilder {
+	key := fmt.Sprintf("%s_startswithcs", field)
+	qb.filters[key] = prefix
+	return qb
+}
+
+// EndsWithCaseSensitive adds a case-sensitive suffix filter
+func (qb *QueryBuilder) EndsWithCaseSensitive(field, suffix string) *QueryBuilder {
+	key := fmt.Sprintf("%s_endswithcs", field)
+	qb.filters[key] = suffix
+	return qb
+}
+
+// Matches adds a regular expression filter (RE2 syntax)
+func (qb *QueryBuilder) Matches(field, pattern string) *QueryBuilder {
+	key := fmt.Sprintf("%s_regex", field)
+	qb.filters[key] = pattern
+	return qb
+}
+
 // Search searches across multiple fields (multi-field OR)
 func (qb *QueryBuilder) Search(searchTerm string, fields ...string) *QueryBuilder {
 	key := strings.Join(fields, "__or__") + "_contains"
@@ -120,27 +151,68 @@ func (qb *QueryBuilder) Search(searchTerm string, fields ...string) *QueryBuilde
 // In adds an "in list" filter
 func (qb *QueryBuilder) In(field string, values ...interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_in", field)
-	strValues := make([]string, len(values))
-	for i, v := range values {
-		strValues[i] = fmt.Sprintf("%v", v)
-	}
-	qb.filters[key] = strings.Join(strValues, ",")
+	qb.filters[key] = qb.joinValues(values)
 	return qb
 }
 
 // NotIn adds a "not in list" filter
 func (qb *QueryBuilder) NotIn(field string, values ...interface{}) *QueryBuilder {
 	key := fmt.Sprintf("%s_notin", field)
+	qb.filters[key] = qb.joinValues(values)
+	return qb
+}
+
+// ============================================
+// ARRAY OPERATORS
+// ============================================
+
+// ArrayContains adds a filter for list fields containing value
+func (qb *QueryBuilder) ArrayContains(field string, value interface{}) *QueryBuilder {
+	key := fmt.Sprintf("%s_arraycontains", field)
+	qb.filters[key] = qb.formatValue(value)
+	return qb
+}
+
+// ArrayContainsAny adds a filter for list fields containing at least one of values
+func (qb *QueryBuilder) ArrayContainsAny(field string, values ...interface{}) *QueryBuilder {
+	key := fmt.Sprintf("%s_arraycontainsany", field)
+	qb.filters[key] = qb.joinValues(values)
+	return qb
+}
+
+// ArrayContainsAll adds a filter for list fields containing every one of values
+func (qb *QueryBuilder) ArrayContainsAll(field string, values ...interface{}) *QueryBuilder {
+	key := fmt.Sprintf("%s_arraycontainsall", field)
+	qb.filters[key] = qb.joinValues(values)
+	return qb
+}
+
+// SizeEquals adds a filter on the number of elements in a list field
+func (qb *QueryBuilder) SizeEquals(field string, size int) *QueryBuilder {
+	key := fmt.Sprintf("%s_size", field)
+	qb.filters[key] = fmt.Sprintf("%d", size)
+	return qb
+}
+
+func (qb *QueryBuilder) joinValues(values []interface{}) string {
 	strValues := make([]string, len(values))
 	for i, v := range values {
-		strValues[i] = fmt.Sprintf("%v", v)
+		strValues[i] = qb.formatValue(v)
 	}
-	qb.filters[key] = strings.Join(strValues, ",")
-	return qb
+	return strings.Join(strValues, ",")
+}
+
+// formatValue converts a filter value to its query string form, recording
+// any template placeholders it encounters
+func (qb *QueryBuilder) formatValue(value interface{}) string {
+	if p, ok := value.(Placeholder); ok {
+		qb.params[p.Name] = p
+	}
+	return fmt.Sprintf("%v", value)
 }
 
 // ============================================
-// NULL CHECKS
+// NULL / EXISTENCE CHECKS
 // ============================================
 
 // IsNull adds a null check filter
@@ -157,6 +229,20 @@ func (qb *QueryBuilder) IsNotNull(field string) *QueryBuilder {
 	return qb
 }
 
+// Exists adds a filter for documents where field is present, even if null
+func (qb *QueryBuilder) Exists(field string) *QueryBuilder {
+	key := fmt.Sprintf("%s_exists", field)
+	qb.filters[key] = "true"
+	return qb
+}
+
+// NotExists adds a filter for documents where field is absent
+func (qb *QueryBuilder) NotExists(field string) *QueryBuilder {
+	key := fmt.Sprintf("%s_exists", field)
+	qb.filters[key] = "false"
+	return qb
+}
+
 // ============================================
 // BOOLEAN LOGIC (Simple OR)
 // ============================================
@@ -233,6 +319,66 @@ func (ob *OrBuilder) EndsWith(field, suffix string) *OrBuilder {
 	return ob.addCondition(field, "endswith", suffix)
 }
 
+// ContainsCaseSensitive adds a case-sensitive contains OR condition
+func (ob *OrBuilder) ContainsCaseSensitive(field, substring string) *OrBuilder {
+	return ob.addCondition(field, "containscs", substring)
+}
+
+// StartsWithCaseSensitive adds a case-sensitive startswith OR condition
+func (ob *OrBuilder) StartsWithCaseSensitive(field, prefix string) *OrBuilder {
+	return ob.addCondition(field, "startswithcs", prefix)
+}
+
+// EndsWithCaseSensitive adds a case-sensitive endswith OR condition
+func (ob *OrBuilder) EndsWithCaseSensitive(field, suffix string) *OrBuilder {
+	return ob.addCondition(field, "endswithcs", suffix)
+}
+
+// Matches adds a regular expression OR condition
+func (ob *OrBuilder) Matches(field, pattern string) *OrBuilder {
+	return ob.addCondition(field, "regex", pattern)
+}
+
+// In adds an "in list" OR condition
+func (ob *OrBuilder) In(field string, values ...interface{}) *OrBuilder {
+	return ob.addCondition(field, "in", ob.qb.joinValues(values))
+}
+
+// NotIn adds a "not in list" OR condition
+func (ob *OrBuilder) NotIn(field string, values ...interface{}) *OrBuilder {
+	return ob.addCondition(field, "notin", ob.qb.joinValues(values))
+}
+
+// ArrayContains adds an array contains OR condition
+func (ob *OrBuilder) ArrayContains(field string, value interface{}) *OrBuilder {
+	return ob.addCondition(field, "arraycontains", value)
+}
+
+// ArrayContainsAny adds an array contains-any OR condition
+func (ob *OrBuilder) ArrayContainsAny(field string, values ...interface{}) *OrBuilder {
+	return ob.addCondition(field, "arraycontainsany", ob.qb.joinValues(values))
+}
+
+// ArrayContainsAll adds an array contains-all OR condition
+func (ob *OrBuilder) ArrayContainsAll(field string, values ...interface{}) *OrBuilder {
+	return ob.addCondition(field, "arraycontainsall", ob.qb.joinValues(values))
+}
+
+// SizeEquals adds an array size OR condition
+func (ob *OrBuilder) SizeEquals(field string, size int) *OrBuilder {
+	return ob.addCondition(field, "size", size)
+}
+
+// Exists adds a field-present OR condition
+func (ob *OrBuilder) Exists(field string) *OrBuilder {
+	return ob.addCondition(field, "exists", true)
+}
+
+// NotExists adds a field-absent OR condition
+func (ob *OrBuilder) NotExists(field string) *OrBuilder {
+	return ob.addCondition(field, "exists", false)
+}
+
 // IsNull adds a null check OR condition
 func (ob *OrBuilder) IsNull(field string) *OrBuilder {
 	return ob.addCondition(field, "isnull", true)
@@ -261,7 +407,7 @@ func (ob *OrBuilder) addCondition(field, operator string, value interface{}) *Or
 		prefix = fmt.Sprintf("[or:%s]", ob.groupName)
 	}
 
-	filterStr := fmt.Sprintf("%s%s=%v", prefix, key, value)
+	filterStr := fmt.Sprintf("%s%s=%s", prefix, key, ob.qb.formatValue(value))
 	ob.qb.orFilters[ob.groupName] = append(ob.qb.orFilters[ob.groupName], filterStr)
 	return ob
 }
@@ -300,6 +446,7 @@ func (qb *QueryBuilder) Page(page, perPage int) *QueryBuilder {
 func (qb *QueryBuilder) OrderBy(field string) *QueryBuilder {
 	qb.sort = field
 	qb.order = "asc"
+	qb.sortOrigin = nil
 	return qb
 }
 
@@ -307,6 +454,7 @@ func (qb *QueryBuilder) OrderBy(field string) *QueryBuilder {
 func (qb *QueryBuilder) OrderByAsc(field string) *QueryBuilder {
 	qb.sort = field
 	qb.order = "asc"
+	qb.sortOrigin = nil
 	return qb
 }
 
@@ -314,6 +462,7 @@ func (qb *QueryBuilder) OrderByAsc(field string) *QueryBuilder {
 func (qb *QueryBuilder) OrderByDesc(field string) *QueryBuilder {
 	qb.sort = field
 	qb.order = "desc"
+	qb.sortOrigin = nil
 	return qb
 }
 
@@ -366,11 +515,37 @@ func (qb *QueryBuilder) Build() string {
 		if qb.order != "" {
 			params.Add("order", qb.order)
 		}
+		if qb.sortOrigin != nil {
+			params.Add("sort_origin", qb.sortOrigin.String())
+		}
 	}
 
 	return params.Encode()
 }
 
+// clone returns a deep copy of the builder
+func (qb *QueryBuilder) clone() *QueryBuilder {
+	c := NewQuery()
+	for k, v := range qb.filters {
+		c.filters[k] = v
+	}
+	for group, filters := range qb.orFilters {
+		c.orFilters[group] = append([]string(nil), filters...)
+	}
+	c.limit = qb.limit
+	c.offset = qb.offset
+	c.sort = qb.sort
+	c.order = qb.order
+	if qb.sortOrigin != nil {
+		origin := *qb.sortOrigin
+		c.sortOrigin = &origin
+	}
+	for name, p := range qb.params {
+		c.params[name] = p
+	}
+	return c
+}
+
 // ============================================
 // HELPER METHODS FOR COMMON PATTERNS
 // ============================================
@@ -0,0 +1,254 @@
+package cocobase
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderRetryAfter         = "Retry-After"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+
+	// defaultRetryAfter is how long to back off after a 429 without a
+	// Retry-After header
+	defaultRetryAfter = time.Second
+)
+
+// RateLimit paces requests with a token bucket and caps concurrency.
+// Zero values mean unlimited.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate
+	RequestsPerSecond float64
+	// Burst is the number of requests that may be sent at once
+	// (default: RequestsPerSecond rounded up)
+	Burst int
+	// MaxInFlight is the maximum number of concurrent requests
+	MaxInFlight int
+}
+
+// RateLimitConfig configures client-side rate limiting. Requests wait for
+// both the global limit and their collection's limit, giving up when their
+// context is done.
+type RateLimitConfig struct {
+	// Global applies to every request
+	Global RateLimit
+	// PerCollection applies additional limits to requests on a collection
+	PerCollection map[string]RateLimit
+	// DisableAdaptive stops the client from pausing when the server answers
+	// 429 or reports X-RateLimit-Remaining: 0
+	DisableAdaptive bool
+}
+
+type rateLimiter struct {
+	global        *limit
+	perCollection map[string]*limit
+	adaptive      bool
+}
+
+type limit struct {
+	bucket   *tokenBucket
+	inFlight chan struct{}
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	rl := &rateLimiter{
+		global:        newLimit(config.Global),
+		perCollection: make(map[string]*limit, len(config.PerCollection)),
+		adaptive:      !config.DisableAdaptive,
+	}
+	for collection, l := range config.PerCollection {
+		rl.perCollection[collection] = newLimit(l)
+	}
+	return rl
+}
+
+func newLimit(config RateLimit) *limit {
+	l := &limit{bucket: newTokenBucket(config.RequestsPerSecond, config.Burst)}
+	if config.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, config.MaxInFlight)
+	}
+	return l
+}
+
+// acquire waits for a token and a concurrency slot. The returned function
+// releases the slot.
+func (l *limit) acquire(ctx context.Context) (func(), error) {
+	if err := l.bucket.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.inFlight <- struct{}{}:
+		return func() { <-l.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (rl *rateLimiter) middleware(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request, op Operation) (*http.Response, error) {
+		ctx := req.Context()
+
+		// The collection's limit comes first, so a request waiting on it
+		// does not hold a global slot other collections could use
+		if l, ok := rl.perCollection[op.Collection]; ok && op.Collection != "" {
+			releaseCollection, err := l.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer releaseCollection()
+		}
+
+		release, err := rl.global.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		resp, err := next(req, op)
+		if err == nil && rl.adaptive && !perRecipientLimit(op) {
+			if until, ok := rateLimitPause(resp, time.Now()); ok {
+				rl.global.bucket.pause(until)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// perRecipientLimit reports whether the server limits op per email address
+// rather than per client, so its 429s must not pause other requests
+func perRecipientLimit(op Operation) bool {
+	return op.Name == OpAuthSendMagicLink || op.Name == OpAuthSendLoginCode
+}
+
+// rateLimitPause reports until when the client should stop sending requests
+// based on a 429 response or rate limit headers
+func rateLimitPause(resp *http.Response, now time.Time) (time.Time, bool) {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(resp.Header.Get(HeaderRetryAfter), now); ok {
+			return now.Add(d), true
+		}
+		if reset, ok := parseRateLimitReset(resp.Header.Get(HeaderRateLimitReset), now); ok {
+			return reset, true
+		}
+		return now.Add(defaultRetryAfter), true
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get(HeaderRateLimitRemaining))
+	if err != nil || remaining > 0 {
+		return time.Time{}, false
+	}
+	return parseRateLimitReset(resp.Header.Get(HeaderRateLimitReset), now)
+}
+
+// parseRetryAfter accepts delay-seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset accepts a Unix timestamp or a number of seconds from now
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	// Anything that large is an absolute timestamp, not a delay
+	if n > 1e9 {
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	}
+	return now.Add(time.Duration(n * float64(time.Second))), true
+}
+
+// tokenBucket is a token bucket that can also be paused until a given time
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	b := &tokenBucket{rate: rate, burst: float64(burst)}
+	if b.burst <= 0 {
+		b.burst = math.Max(1, math.Ceil(rate))
+	}
+	b.tokens = b.burst
+	return b
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		delay, ok := b.reserve(time.Now())
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available, or reports how long to wait
+func (b *tokenBucket) reserve(now time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now), false
+	}
+	if b.rate <= 0 {
+		return 0, true
+	}
+
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second)), false
+}
+
+func (b *tokenBucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	// Start from an empty bucket so requests resume at the sustained rate
+	// rather than in a burst
+	b.tokens = 0
+	b.last = until
+}
@@ -14,6 +14,7 @@ func (c *Client) WatchCollection(ctx context.Context, collection string, callbac
 	
 	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
 	if err != nil {
+		c.logger.Warn("realtime connect failed", "collection", collection, "error", err.Error())
 		return nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
 
@@ -33,6 +34,9 @@ func (c *Client) WatchCollection(ctx context.Context, collection string, callbac
 		closed: false,
 	}
 
+	logger := c.logger.With("collection", collection, "connection", name)
+	logger.Info("realtime connected")
+
 	go func() {
 		defer func() {
 			connection.mu.Lock()
@@ -45,7 +49,9 @@ func (c *Client) WatchCollection(ctx context.Context, collection string, callbac
 			err := conn.ReadJSON(&event)
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					fmt.Printf("WebSocket error: %v\n", err)
+					logger.Warn("realtime disconnected", "error", err.Error())
+				} else {
+					logger.Info("realtime disconnected")
 				}
 				return
 			}
@@ -0,0 +1,210 @@
+package cocobase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts  = 3
+	DefaultBackoff      = 200 * time.Millisecond
+	DefaultMaxBackoff   = 5 * time.Second
+	DefaultDedupeWindow = 10 * time.Minute
+)
+
+// RetryConfig retries requests that are safe to repeat: GET, HEAD, PUT and
+// DELETE requests, and writes carrying an Idempotency-Key (every
+// CreateDocument call has one). Transport errors, 429 and 5xx responses are
+// retried with exponential backoff, honouring Retry-After.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first (default 3)
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for each one (default 200ms)
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts (default 5s)
+	MaxBackoff time.Duration
+}
+
+func normalizeRetry(config *RetryConfig) *RetryConfig {
+	if config == nil {
+		return nil
+	}
+	r := *config
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = DefaultMaxAttempts
+	}
+	if r.Backoff <= 0 {
+		r.Backoff = DefaultBackoff
+	}
+	if r.MaxBackoff <= 0 {
+		r.MaxBackoff = DefaultMaxBackoff
+	}
+	return &r
+}
+
+// retryDelay reports whether a failed attempt should be retried and after
+// how long
+func (c *Client) retryDelay(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	r := c.retry
+	if r == nil || attempt >= r.MaxAttempts || ctx.Err() != nil || !retryableRequest(req) {
+		return 0, false
+	}
+
+	if err != nil {
+		// Failing fast is the point of an open circuit
+		if errors.Is(err, ErrCircuitOpen) {
+			return 0, false
+		}
+	} else {
+		if !retryableStatus(resp.StatusCode) {
+			return 0, false
+		}
+		if d, ok := parseRetryAfter(resp.Header.Get(HeaderRetryAfter), time.Now()); ok {
+			return min(d, r.MaxBackoff), true
+		}
+	}
+
+	return r.backoff(attempt), true
+}
+
+// backoff doubles Backoff for each attempt after the first, capped at
+// MaxBackoff without overflowing
+func (r *RetryConfig) backoff(attempt int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < attempt && d <= r.MaxBackoff/2; i++ {
+		d *= 2
+	}
+	return min(d, r.MaxBackoff)
+}
+
+func retryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newIdempotencyKey returns a random UUID (version 4)
+func newIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ============================================
+// DUPLICATE CREATES
+// ============================================
+
+// dedupeStore remembers documents created recently by idempotency key, so a
+// replayed create that the server rejects as a duplicate returns the original
+type dedupeStore struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries map[string]dedupeEntry
+}
+
+type dedupeEntry struct {
+	doc     Document
+	expires time.Time
+}
+
+func newDedupeStore(window time.Duration) *dedupeStore {
+	if window < 0 {
+		return nil
+	}
+	if window == 0 {
+		window = DefaultDedupeWindow
+	}
+	return &dedupeStore{window: window, entries: make(map[string]dedupeEntry)}
+}
+
+func (d *dedupeStore) put(collection, key string, doc Document) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range d.entries {
+		if now.After(e.expires) {
+			delete(d.entries, k)
+		}
+	}
+	d.entries[collection+"\x00"+key] = dedupeEntry{doc: doc, expires: now.Add(d.window)}
+}
+
+func (d *dedupeStore) get(collection, key string) (*Document, bool) {
+	if d == nil {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.entries[collection+"\x00"+key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	doc := e.doc
+	return &doc, true
+}
+
+// duplicateCreate returns the original document when err is the server
+// rejecting a create as a duplicate (409 Conflict) of one with the same
+// idempotency key, either from the dedupe window or from a response marked
+// with Idempotent-Replayed. Other conflicts, such as a unique field
+// violation, are left as errors.
+func (c *Client) duplicateCreate(collection, key string, err error) (*Document, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		return nil, false
+	}
+
+	if doc, ok := c.dedupe.get(collection, key); ok {
+		return doc, true
+	}
+
+	if replayed, _ := strconv.ParseBool(apiErr.Header.Get(HeaderIdempotentReplayed)); !replayed {
+		return nil, false
+	}
+	var doc Document
+	if c.unmarshal([]byte(apiErr.Body), &doc) == nil && doc.ID != "" {
+		return &doc, true
+	}
+	return nil, false
+}
@@ -0,0 +1,618 @@
+package cocobase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchMode controls how the terms of a search query are combined
+type SearchMode string
+
+const (
+	// SearchMatchAll requires every term to match (default)
+	SearchMatchAll SearchMode = "all"
+	// SearchMatchAny requires at least one term to match
+	SearchMatchAny SearchMode = "any"
+	// SearchPhrase requires the terms to appear consecutively in one field
+	SearchPhrase SearchMode = "phrase"
+)
+
+// SearchRequest describes a full-text search
+type SearchRequest struct {
+	// Query is the text to search for
+	Query string
+	// Fields restricts the search to these fields; empty searches all text fields
+	Fields []string
+	// Boosts multiplies the score of matches in a field (default 1)
+	Boosts map[string]float64
+	// Mode controls how terms are combined
+	Mode SearchMode
+	// Prefix treats the last term as a prefix ("cocob" matches "cocobase")
+	Prefix bool
+	// Typo enables fuzzy matching of misspelled terms
+	Typo *TypoTolerance
+	// Highlight returns snippets with matched terms wrapped in tags
+	Highlight *HighlightOptions
+	// Filter narrows the candidates with a regular query
+	Filter *QueryBuilder
+	Limit  int
+	Offset int
+}
+
+// TypoTolerance configures fuzzy matching
+type TypoTolerance struct {
+	// MaxEdits is the maximum edit distance between a query term and a match (default 1)
+	MaxEdits int `json:"max_edits"`
+	// MinWordLength is the shortest term that fuzzy matching applies to (default 4)
+	MinWordLength int `json:"min_word_length"`
+}
+
+// HighlightOptions configures highlighted snippets
+type HighlightOptions struct {
+	PreTag  string `json:"pre_tag"`
+	PostTag string `json:"post_tag"`
+	// SnippetLength is the approximate snippet size in characters (default 120)
+	SnippetLength int `json:"snippet_length"`
+}
+
+// SearchHit is a single ranked search result
+type SearchHit struct {
+	Document   Document            `json:"document"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+// SearchResult holds the ranked hits of a search
+type SearchResult struct {
+	Hits  []SearchHit `json:"hits"`
+	Total int         `json:"total"`
+	// Local is true when the result came from a local SearchIndex
+	Local bool `json:"-"`
+}
+
+// SearchDocuments runs a full-text search on the server. When the server is
+// unreachable or has no search endpoint and a local index was registered with
+// UseLocalSearchIndex, the search runs against that index instead.
+func (c *Client) SearchDocuments(ctx context.Context, collection string, req SearchRequest, opts ...CallOption) (*SearchResult, error) {
+	path := fmt.Sprintf("/collections/%s/search", collection)
+
+	body := map[string]interface{}{
+		"query": req.Query,
+	}
+	if len(req.Fields) > 0 {
+		body["fields"] = req.Fields
+	}
+	if len(req.Boosts) > 0 {
+		body["boosts"] = req.Boosts
+	}
+	if req.Mode != "" {
+		body["mode"] = req.Mode
+	}
+	if req.Prefix {
+		body["prefix"] = true
+	}
+	if req.Typo != nil {
+		body["typo_tolerance"] = req.Typo
+	}
+	if req.Highlight != nil {
+		body["highlight"] = req.Highlight
+	}
+	if req.Filter != nil {
+		body["filter"] = req.Filter.Build()
+	}
+	if req.Limit > 0 {
+		body["limit"] = req.Limit
+	}
+	if req.Offset > 0 {
+		body["offset"] = req.Offset
+	}
+
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsSearch, Collection: collection}, http.MethodPost, path, body, false, opts...)
+	if err != nil {
+		if idx := c.localSearchIndex(collection); idx != nil && searchUnavailable(err) {
+			result := idx.Search(req)
+			return &result, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result SearchResult
+	if err := c.decode(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// UseLocalSearchIndex registers idx as the offline fallback for searches on
+// collection. The client keeps it up to date with its own document writes.
+// Passing nil removes the index.
+func (c *Client) UseLocalSearchIndex(collection string, idx *SearchIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if idx == nil {
+		delete(c.searchIndexes, collection)
+		return
+	}
+	if c.searchIndexes == nil {
+		c.searchIndexes = make(map[string]*SearchIndex)
+	}
+	c.searchIndexes[collection] = idx
+}
+
+func (c *Client) localSearchIndex(collection string) *SearchIndex {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.searchIndexes[collection]
+}
+
+func searchUnavailable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// ============================================
+// LOCAL INVERTED INDEX
+// ============================================
+
+// SearchIndex is an in-memory inverted index over documents, used for
+// offline search. It is safe for concurrent use.
+type SearchIndex struct {
+	mu       sync.RWMutex
+	fields   []string
+	docs     map[string]Document
+	tokens   map[string]map[string][]searchToken
+	postings map[string]map[string]int
+}
+
+type searchToken struct {
+	term  string
+	start int
+	end   int
+}
+
+// NewSearchIndex creates an index over fields. With no fields, every
+// top-level string field of a document is indexed.
+func NewSearchIndex(fields ...string) *SearchIndex {
+	return &SearchIndex{
+		fields:   fields,
+		docs:     make(map[string]Document),
+		tokens:   make(map[string]map[string][]searchToken),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Add indexes docs, replacing any previously indexed version with the same ID
+func (idx *SearchIndex) Add(docs ...Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, doc := range docs {
+		idx.remove(doc.ID)
+
+		fields := make(map[string][]searchToken)
+		for _, field := range idx.fieldsOf(doc) {
+			text, ok := searchText(doc, field)
+			if !ok {
+				continue
+			}
+			tokens := tokenize(text)
+			if len(tokens) == 0 {
+				continue
+			}
+			fields[field] = tokens
+			for _, tok := range tokens {
+				if idx.postings[tok.term] == nil {
+					idx.postings[tok.term] = make(map[string]int)
+				}
+				idx.postings[tok.term][doc.ID]++
+			}
+		}
+
+		idx.docs[doc.ID] = doc
+		idx.tokens[doc.ID] = fields
+	}
+}
+
+// Remove drops the document with id from the index
+func (idx *SearchIndex) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+}
+
+// Len returns the number of indexed documents
+func (idx *SearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+func (idx *SearchIndex) remove(id string) {
+	fields, ok := idx.tokens[id]
+	if !ok {
+		return
+	}
+	for _, tokens := range fields {
+		for _, tok := range tokens {
+			if docs := idx.postings[tok.term]; docs != nil {
+				delete(docs, id)
+				if len(docs) == 0 {
+					delete(idx.postings, tok.term)
+				}
+			}
+		}
+	}
+	delete(idx.tokens, id)
+	delete(idx.docs, id)
+}
+
+func (idx *SearchIndex) fieldsOf(doc Document) []string {
+	if len(idx.fields) > 0 {
+		return idx.fields
+	}
+	fields := make([]string, 0, len(doc.Data))
+	for field := range doc.Data {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Search ranks indexed documents against req using TF-IDF scoring with
+// per-field boosts.
+func (idx *SearchIndex) Search(req SearchRequest) SearchResult {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	result := SearchResult{Hits: []SearchHit{}, Local: true}
+
+	queryTokens := tokenize(req.Query)
+	if len(queryTokens) == 0 {
+		return result
+	}
+
+	// Expand each query term to the indexed terms it matches, with a weight
+	// that favours exact matches over prefix and fuzzy ones.
+	variants := make([]map[string]float64, len(queryTokens))
+	for i, tok := range queryTokens {
+		prefix := req.Prefix && i == len(queryTokens)-1
+		variants[i] = idx.expandTerm(tok.term, prefix, req.Typo)
+	}
+
+	allowed := make(map[string]bool, len(req.Fields))
+	for _, field := range req.Fields {
+		allowed[field] = true
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = SearchMatchAll
+	}
+
+	var filter *matcher
+	if req.Filter != nil {
+		filter = req.Filter.matcher()
+	}
+
+	for id, fields := range idx.tokens {
+		doc := idx.docs[id]
+		if filter != nil && !filter.match(doc) {
+			continue
+		}
+
+		score := 0.0
+		matchedTerms := make([]bool, len(variants))
+		matchedFields := make(map[string]map[int]bool)
+
+		for field, tokens := range fields {
+			if len(allowed) > 0 && !allowed[field] {
+				continue
+			}
+
+			boost := 1.0
+			if b, ok := req.Boosts[field]; ok {
+				boost = b
+			}
+			norm := 1 / math.Sqrt(float64(len(tokens)))
+
+			if mode == SearchPhrase {
+				for _, start := range findPhrase(tokens, variants) {
+					if matchedFields[field] == nil {
+						matchedFields[field] = make(map[int]bool)
+					}
+					for i := range variants {
+						matchedFields[field][start+i] = true
+						matchedTerms[i] = true
+						score += variants[i][tokens[start+i].term] * idx.idf(tokens[start+i].term) * boost * norm
+					}
+				}
+				continue
+			}
+
+			for pos, tok := range tokens {
+				for i, terms := range variants {
+					weight, ok := terms[tok.term]
+					if !ok {
+						continue
+					}
+					if matchedFields[field] == nil {
+						matchedFields[field] = make(map[int]bool)
+					}
+					matchedFields[field][pos] = true
+					matchedTerms[i] = true
+					score += weight * idx.idf(tok.term) * boost * norm
+				}
+			}
+		}
+
+		if !searchMatched(mode, matchedTerms) || score <= 0 {
+			continue
+		}
+
+		hit := SearchHit{Document: doc, Score: score}
+		if req.Highlight != nil {
+			hit.Highlights = make(map[string][]string)
+			for field, positions := range matchedFields {
+				text, _ := searchText(doc, field)
+				hit.Highlights[field] = []string{highlight(text, fields[field], positions, *req.Highlight)}
+			}
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+
+	sort.SliceStable(result.Hits, func(i, j int) bool {
+		if result.Hits[i].Score != result.Hits[j].Score {
+			return result.Hits[i].Score > result.Hits[j].Score
+		}
+		return result.Hits[i].Document.ID < result.Hits[j].Document.ID
+	})
+
+	result.Total = len(result.Hits)
+	if req.Offset > 0 {
+		if req.Offset >= len(result.Hits) {
+			result.Hits = []SearchHit{}
+			return result
+		}
+		result.Hits = result.Hits[req.Offset:]
+	}
+	if req.Limit > 0 && req.Limit < len(result.Hits) {
+		result.Hits = result.Hits[:req.Limit]
+	}
+
+	return result
+}
+
+func (idx *SearchIndex) idf(term string) float64 {
+	n := float64(len(idx.docs))
+	df := float64(len(idx.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+func (idx *SearchIndex) expandTerm(term string, prefix bool, typo *TypoTolerance) map[string]float64 {
+	terms := make(map[string]float64)
+	if _, ok := idx.postings[term]; ok {
+		terms[term] = 1
+	}
+
+	maxEdits, minLength := 0, 0
+	if typo != nil {
+		maxEdits, minLength = typo.MaxEdits, typo.MinWordLength
+		if maxEdits <= 0 {
+			maxEdits = 1
+		}
+		if minLength <= 0 {
+			minLength = 4
+		}
+	}
+
+	for candidate := range idx.postings {
+		if candidate == term {
+			continue
+		}
+		if prefix && strings.HasPrefix(candidate, term) {
+			terms[candidate] = math.Max(terms[candidate], 0.8)
+			continue
+		}
+		if maxEdits > 0 && utf8.RuneCountInString(term) >= minLength {
+			if d := editDistance(term, candidate, maxEdits); d <= maxEdits {
+				terms[candidate] = math.Max(terms[candidate], 0.5/float64(d))
+			}
+		}
+	}
+
+	return terms
+}
+
+func searchMatched(mode SearchMode, matched []bool) bool {
+	hasAny, all := false, true
+	for _, m := range matched {
+		hasAny = hasAny || m
+		all = all && m
+	}
+	if mode == SearchMatchAny {
+		return hasAny
+	}
+	return all
+}
+
+// findPhrase returns the start positions where the query terms appear
+// consecutively in tokens
+func findPhrase(tokens []searchToken, variants []map[string]float64) []int {
+	var starts []int
+	for start := 0; start+len(variants) <= len(tokens); start++ {
+		matched := true
+		for i, terms := range variants {
+			if _, ok := terms[tokens[start+i].term]; !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+func searchText(doc Document, field string) (string, bool) {
+	value, ok := lookupField(doc, field)
+	if !ok {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, " "), len(parts) > 0
+	}
+
+	return "", false
+}
+
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			tokens = append(tokens, searchToken{term: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// highlight builds a snippet around the first matched token, wrapping every
+// matched token inside the snippet in the configured tags.
+func highlight(text string, tokens []searchToken, positions map[int]bool, opts HighlightOptions) string {
+	if opts.PreTag == "" && opts.PostTag == "" {
+		opts.PreTag, opts.PostTag = "<mark>", "</mark>"
+	}
+	if opts.SnippetLength <= 0 {
+		opts.SnippetLength = 120
+	}
+
+	first := -1
+	for pos := range positions {
+		if first < 0 || pos < first {
+			first = pos
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	start := tokens[first].start - opts.SnippetLength/4
+	if start < 0 {
+		start = 0
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := start + opts.SnippetLength
+	if end > len(text) {
+		end = len(text)
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	cursor := start
+	for pos, tok := range tokens {
+		if !positions[pos] || tok.start < start || tok.end > end {
+			continue
+		}
+		b.WriteString(text[cursor:tok.start])
+		b.WriteString(opts.PreTag)
+		b.WriteString(text[tok.start:tok.end])
+		b.WriteString(opts.PostTag)
+		cursor = tok.end
+	}
+	b.WriteString(text[cursor:end])
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// editDistance returns the Levenshtein distance between a and b, stopping
+// early once it exceeds max
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
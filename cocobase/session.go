@@ -0,0 +1,228 @@
+package cocobase
+
+import (
+	"context"
+	"time"
+)
+
+type userTokenContextKey struct{}
+
+// WithUserToken returns a context whose calls authenticate as the user owning
+// token instead of the client's signed-in user. A WithBearerToken or
+// WithoutAuth call option still takes precedence.
+func WithUserToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, userTokenContextKey{}, token)
+}
+
+// UserTokenFromContext returns the token set with WithUserToken
+func UserTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(userTokenContextKey{}).(string)
+	return token, ok
+}
+
+// Session is an end user's identity, independent of the client's own
+// signed-in user. Many sessions can share one Client concurrently, which
+// makes the client's global auth state optional on servers acting for many
+// users.
+type Session struct {
+	Token string
+	User  *AppUser
+
+	// RefreshToken and ExpiresAt are set when the server issued them
+	RefreshToken string
+	ExpiresAt    time.Time
+
+	client *Client
+}
+
+// NewSession creates a session for an existing token and user
+func (c *Client) NewSession(token string, user *AppUser) *Session {
+	return &Session{Token: token, User: user, client: c}
+}
+
+// ResumeSession creates a session for token, fetching its user
+func (c *Client) ResumeSession(ctx context.Context, token string, opts ...CallOption) (*Session, error) {
+	user, err := c.GetCurrentUser(WithUserToken(ctx, token), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewSession(token, user), nil
+}
+
+// LoginSession signs in and returns a session, leaving the client's own
+// auth state untouched. If the account requires MFA, the error is an
+// *MFAChallenge to pass to VerifyMFASession.
+func (c *Client) LoginSession(ctx context.Context, email, password string, opts ...CallOption) (*Session, error) {
+	body := map[string]string{
+		"email":    email,
+		"password": password,
+	}
+	return c.authenticate(ctx, Operation{Name: OpAuthLogin}, "/auth-collections/login", body, opts)
+}
+
+// RegisterSession signs up and returns a session, leaving the client's own
+// auth state untouched
+func (c *Client) RegisterSession(ctx context.Context, email, password string, data map[string]interface{}, opts ...CallOption) (*Session, error) {
+	body := map[string]interface{}{
+		"email":    email,
+		"password": password,
+	}
+	if data != nil {
+		body["data"] = data
+	}
+	return c.authenticate(ctx, Operation{Name: OpAuthRegister}, "/auth-collections/signup", body, opts)
+}
+
+// Context returns ctx carrying the session's token
+func (s *Session) Context(ctx context.Context) context.Context {
+	return WithUserToken(ctx, s.Token)
+}
+
+// Refresh exchanges the session's refresh token for a new access token.
+// Sessions are not refreshed automatically; check ExpiresAt before use.
+func (s *Session) Refresh(ctx context.Context, opts ...CallOption) error {
+	if s.RefreshToken == "" {
+		return ErrNoRefreshToken
+	}
+
+	tokens, err := s.client.exchangeRefreshToken(ctx, s.RefreshToken, opts)
+	if err != nil {
+		return err
+	}
+
+	s.Token = tokens.AccessToken
+	if tokens.RefreshToken != "" {
+		s.RefreshToken = tokens.RefreshToken
+	}
+	s.ExpiresAt = tokens.expiresAt(time.Now())
+	return nil
+}
+
+func (s *Session) IsAuthenticated() bool {
+	return s.Token != ""
+}
+
+func (s *Session) HasRole(role string) bool {
+	if s.User == nil {
+		return false
+	}
+	for _, r := range s.User.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) GetDocument(ctx context.Context, collection, docID string, opts ...CallOption) (*Document, error) {
+	return s.client.GetDocument(s.Context(ctx), collection, docID, opts...)
+}
+
+func (s *Session) CreateDocument(ctx context.Context, collection string, data map[string]interface{}, opts ...CallOption) (*Document, error) {
+	return s.client.CreateDocument(s.Context(ctx), collection, data, opts...)
+}
+
+func (s *Session) UpdateDocument(ctx context.Context, collection, docID string, data map[string]interface{}, opts ...CallOption) (*Document, error) {
+	return s.client.UpdateDocument(s.Context(ctx), collection, docID, data, opts...)
+}
+
+func (s *Session) DeleteDocument(ctx context.Context, collection, docID string, opts ...CallOption) error {
+	return s.client.DeleteDocument(s.Context(ctx), collection, docID, opts...)
+}
+
+func (s *Session) ListDocuments(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) ([]Document, error) {
+	return s.client.ListDocuments(s.Context(ctx), collection, query, opts...)
+}
+
+func (s *Session) QueryDocuments(ctx context.Context, collection, rawQuery string, opts ...CallOption) ([]Document, error) {
+	return s.client.QueryDocuments(s.Context(ctx), collection, rawQuery, opts...)
+}
+
+func (s *Session) IterateDocuments(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) (*DocumentIterator, error) {
+	return s.client.IterateDocuments(s.Context(ctx), collection, query, opts...)
+}
+
+func (s *Session) StreamDocuments(ctx context.Context, collection string, query *QueryBuilder, fn func(Document) error, opts ...CallOption) error {
+	return s.client.StreamDocuments(s.Context(ctx), collection, query, fn, opts...)
+}
+
+func (s *Session) SearchDocuments(ctx context.Context, collection string, req SearchRequest, opts ...CallOption) (*SearchResult, error) {
+	return s.client.SearchDocuments(s.Context(ctx), collection, req, opts...)
+}
+
+func (s *Session) ExplainQuery(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) (*QueryPlan, error) {
+	return s.client.ExplainQuery(s.Context(ctx), collection, query, opts...)
+}
+
+func (s *Session) ListDocumentsWithTemplate(ctx context.Context, collection string, tpl *QueryTemplate, params map[string]interface{}, opts ...CallOption) ([]Document, error) {
+	return s.client.ListDocumentsWithTemplate(s.Context(ctx), collection, tpl, params, opts...)
+}
+
+// GetCurrentUser fetches the session's user and stores it in s.User
+func (s *Session) GetCurrentUser(ctx context.Context, opts ...CallOption) (*AppUser, error) {
+	user, err := s.client.GetCurrentUser(s.Context(ctx), opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.User = user
+	return user, nil
+}
+
+// UpdateUser merges data into the session user's data and stores the result
+// in s.User
+func (s *Session) UpdateUser(ctx context.Context, data map[string]interface{}, email, password *string, opts ...CallOption) (*AppUser, error) {
+	if data != nil && s.User != nil {
+		data = mergeData(s.User.Data, data)
+	}
+
+	user, err := s.client.UpdateUser(s.Context(ctx), data, email, password, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.User = user
+	return user, nil
+}
+
+func (s *Session) SendVerificationEmail(ctx context.Context, opts ...CallOption) error {
+	return s.client.SendVerificationEmail(s.Context(ctx), opts...)
+}
+
+// ChangePassword changes the session user's password and, unless the
+// account uses MFA, switches the session to a token issued for the new one
+func (s *Session) ChangePassword(ctx context.Context, currentPassword, newPassword string, opts ...CallOption) error {
+	session, err := s.client.changePassword(s.Context(ctx), currentPassword, newPassword, opts)
+	if err != nil || session == nil {
+		return err
+	}
+
+	s.Token = session.Token
+	s.RefreshToken = session.RefreshToken
+	s.ExpiresAt = session.ExpiresAt
+	s.User = session.User
+	return nil
+}
+
+// EnrollTOTP starts adding an authenticator app for the session user
+func (s *Session) EnrollTOTP(ctx context.Context, opts ...CallOption) (*TOTPEnrollment, error) {
+	enrollment, err := s.client.EnrollTOTP(s.Context(ctx), opts...)
+	if err != nil {
+		return nil, err
+	}
+	// Label the URI the client built with the session user's email
+	if s.User != nil && enrollment.URI == totpURI("Cocobase", "", enrollment.Secret) {
+		enrollment.URI = totpURI("Cocobase", s.User.Email, enrollment.Secret)
+	}
+	return enrollment, nil
+}
+
+func (s *Session) ConfirmTOTP(ctx context.Context, code string, opts ...CallOption) ([]string, error) {
+	return s.client.ConfirmTOTP(s.Context(ctx), code, opts...)
+}
+
+func (s *Session) RegenerateRecoveryCodes(ctx context.Context, code string, opts ...CallOption) ([]string, error) {
+	return s.client.RegenerateRecoveryCodes(s.Context(ctx), code, opts...)
+}
+
+func (s *Session) DisableMFA(ctx context.Context, code string, opts ...CallOption) error {
+	return s.client.DisableMFA(s.Context(ctx), code, opts...)
+}
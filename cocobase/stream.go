@@ -0,0 +1,142 @@
+package cocobase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DocumentIterator decodes a document list one element at a time, so large
+// result sets are never held in memory at once
+//
+//	it, err := client.IterateDocuments(ctx, "events", query)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		process(it.Document())
+//	}
+//	return it.Err()
+type DocumentIterator struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+	doc  Document
+	err  error
+	done bool
+
+	// buffered holds the whole list when the codec cannot stream
+	buffered []Document
+}
+
+// IterateDocuments lists documents like ListDocuments but decodes them
+// lazily. Geospatial filters are sent to the server as-is. Responses are
+// never cached or coalesced, and codecs other than JSONCodec decode the
+// whole list up front. The iterator must be closed.
+func (c *Client) IterateDocuments(ctx context.Context, collection string, query *QueryBuilder, opts ...CallOption) (*DocumentIterator, error) {
+	path := fmt.Sprintf("/collections/%s/documents", collection)
+	if query != nil {
+		if queryStr := query.Build(); queryStr != "" {
+			path += "?" + queryStr
+		}
+	}
+
+	resp, err := c.request(ctx, Operation{Name: OpDocumentsStream, Collection: collection}, http.MethodGet, path, nil, true, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	it := &DocumentIterator{body: resp.Body}
+
+	codec, ok := c.codec.(JSONCodec)
+	if !ok {
+		if err := c.decode(resp.Body, &it.buffered); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return it, nil
+	}
+	it.dec = codec.newDecoder(resp.Body)
+
+	tok, err := it.dec.Token()
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode response: expected a JSON array, got %v", tok)
+	}
+
+	return it, nil
+}
+
+// Next decodes the next document, returning false at the end of the list or
+// on error
+func (it *DocumentIterator) Next() bool {
+	if it.done {
+		return false
+	}
+
+	if it.dec == nil {
+		if len(it.buffered) == 0 {
+			it.done = true
+			return false
+		}
+		it.doc, it.buffered = it.buffered[0], it.buffered[1:]
+		return true
+	}
+
+	if !it.dec.More() {
+		it.done = true
+		if _, err := it.dec.Token(); err != nil {
+			it.err = fmt.Errorf("failed to decode response: %w", err)
+		}
+		return false
+	}
+
+	it.doc = Document{}
+	if err := it.dec.Decode(&it.doc); err != nil {
+		it.done = true
+		it.err = fmt.Errorf("failed to decode response: %w", err)
+		return false
+	}
+
+	return true
+}
+
+// Document returns the document decoded by the last call to Next
+func (it *DocumentIterator) Document() Document {
+	return it.doc
+}
+
+// Err returns the error that stopped iteration, if any
+func (it *DocumentIterator) Err() error {
+	return it.err
+}
+
+// Close releases the response body
+func (it *DocumentIterator) Close() error {
+	it.done = true
+	return it.body.Close()
+}
+
+// StreamDocuments calls fn for each document in the list as it is decoded.
+// Iteration stops at the first error returned by fn, which is returned.
+func (c *Client) StreamDocuments(ctx context.Context, collection string, query *QueryBuilder, fn func(Document) error, opts ...CallOption) error {
+	it, err := c.IterateDocuments(ctx, collection, query, opts...)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		if err := fn(it.Document()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
@@ -0,0 +1,342 @@
+package cocobase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParamType is the expected type of a query template parameter
+type ParamType string
+
+const (
+	ParamString  ParamType = "string"
+	ParamNumber  ParamType = "number"
+	ParamInteger ParamType = "integer"
+	ParamBool    ParamType = "bool"
+	ParamTime    ParamType = "time"
+	ParamList    ParamType = "list"
+)
+
+// Placeholder is a named, typed parameter in a query template. Pass it as a
+// filter value (e.g. Where("status", Param("status", ParamString))) and bind
+// it later with QueryTemplate.Bind.
+type Placeholder struct {
+	Name    string      `json:"name"`
+	Type    ParamType   `json:"type"`
+	Default interface{} `json:"default,omitempty"`
+}
+
+// Param creates a required placeholder
+func Param(name string, typ ParamType) Placeholder {
+	return Placeholder{Name: name, Type: typ}
+}
+
+// WithDefault returns a copy of the placeholder that falls back to value when
+// it is not bound
+func (p Placeholder) WithDefault(value interface{}) Placeholder {
+	p.Default = value
+	return p
+}
+
+// String returns the placeholder token as it appears in the query (":name")
+func (p Placeholder) String() string {
+	return ":" + p.Name
+}
+
+// QueryTemplate is a saved query with named placeholders
+type QueryTemplate struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Params      []Placeholder `json:"params"`
+	Query       *QueryBuilder `json:"query"`
+}
+
+// NewQueryTemplate creates a template from query. Placeholders passed as
+// filter values are picked up automatically; params declares additional
+// ones, e.g. for string operators where the token is written as ":name".
+func NewQueryTemplate(name string, query *QueryBuilder, params ...Placeholder) *QueryTemplate {
+	if query == nil {
+		query = NewQuery()
+	}
+
+	declared := make(map[string]Placeholder)
+	for n, p := range query.params {
+		declared[n] = p
+	}
+	for _, p := range params {
+		declared[p.Name] = p
+	}
+
+	tpl := &QueryTemplate{Name: name, Query: query.clone()}
+	for _, p := range declared {
+		tpl.Params = append(tpl.Params, p)
+	}
+	sort.Slice(tpl.Params, func(i, j int) bool {
+		return tpl.Params[i].Name < tpl.Params[j].Name
+	})
+
+	return tpl
+}
+
+// Bind type-checks params against the template's placeholders and returns a
+// new QueryBuilder with every placeholder replaced by its value
+func (t *QueryTemplate) Bind(params map[string]interface{}) (*QueryBuilder, error) {
+	declared := make(map[string]bool, len(t.Params))
+	values := make(map[string]string, len(t.Params))
+
+	for _, p := range t.Params {
+		declared[p.Name] = true
+
+		value, ok := params[p.Name]
+		if !ok {
+			if p.Default == nil {
+				return nil, fmt.Errorf("missing value for parameter %q", p.Name)
+			}
+			value = p.Default
+		}
+
+		formatted, err := formatParam(p, value)
+		if err != nil {
+			return nil, err
+		}
+		values[":"+p.Name] = formatted
+	}
+
+	for name := range params {
+		if !declared[name] {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+
+	qb := t.Query.clone()
+	qb.params = make(map[string]Placeholder)
+
+	for key, value := range qb.filters {
+		qb.filters[key] = substituteParams(value, values)
+	}
+
+	for group, filters := range qb.orFilters {
+		for i, filter := range filters {
+			end := strings.Index(filter, "]")
+			key, value, ok := parseOrFilter(filter)
+			if !ok {
+				continue
+			}
+			filters[i] = fmt.Sprintf("%s%s=%s", filter[:end+1], key, substituteParams(value, values))
+		}
+		qb.orFilters[group] = filters
+	}
+
+	return qb, nil
+}
+
+// substituteParams replaces a whole value or any element of a comma list that
+// is exactly a placeholder token
+func substituteParams(value string, values map[string]string) string {
+	if v, ok := values[value]; ok {
+		return v
+	}
+	if !strings.Contains(value, ",") {
+		return value
+	}
+
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		if v, ok := values[part]; ok {
+			parts[i] = v
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatParam(p Placeholder, value interface{}) (string, error) {
+	mismatch := func() (string, error) {
+		return "", fmt.Errorf("parameter %q must be %s, got %T", p.Name, p.Type, value)
+	}
+
+	switch p.Type {
+	case ParamString, "":
+		s, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		return s, nil
+	case ParamNumber:
+		if s, ok := value.(string); ok {
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return s, nil
+			}
+			return mismatch()
+		}
+		f, ok := toFloat(value)
+		if !ok {
+			return mismatch()
+		}
+		if n, ok := value.(json.Number); ok {
+			return n.String(), nil
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case ParamInteger:
+		switch v := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return fmt.Sprintf("%d", v), nil
+		case json.Number:
+			if _, err := v.Int64(); err != nil {
+				return mismatch()
+			}
+			return v.String(), nil
+		}
+		// JSON numbers decode as float64; accept them when integral
+		if f, ok := value.(float64); ok && f == math.Trunc(f) {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		return mismatch()
+	case ParamBool:
+		b, ok := value.(bool)
+		if !ok {
+			return mismatch()
+		}
+		return strconv.FormatBool(b), nil
+	case ParamTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		case string:
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				return "", fmt.Errorf("parameter %q must be an RFC 3339 time: %w", p.Name, err)
+			}
+			return v, nil
+		}
+		return mismatch()
+	case ParamList:
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+			return mismatch()
+		}
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = fmt.Sprintf("%v", rv.Index(i).Interface())
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	return "", fmt.Errorf("parameter %q has unknown type %q", p.Name, p.Type)
+}
+
+// ============================================
+// SERIALIZATION
+// ============================================
+
+type queryJSON struct {
+	Filters    map[string]string   `json:"filters,omitempty"`
+	OrFilters  map[string][]string `json:"or_filters,omitempty"`
+	Limit      int                 `json:"limit,omitempty"`
+	Offset     int                 `json:"offset,omitempty"`
+	Sort       string              `json:"sort,omitempty"`
+	Order      string              `json:"order,omitempty"`
+	SortOrigin *GeoPoint           `json:"sort_origin,omitempty"`
+}
+
+// MarshalJSON encodes the query so it can be stored and restored later
+func (qb *QueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(queryJSON{
+		Filters:    qb.filters,
+		OrFilters:  qb.orFilters,
+		Limit:      qb.limit,
+		Offset:     qb.offset,
+		Sort:       qb.sort,
+		Order:      qb.order,
+		SortOrigin: qb.sortOrigin,
+	})
+}
+
+// UnmarshalJSON restores a query encoded with MarshalJSON
+func (qb *QueryBuilder) UnmarshalJSON(data []byte) error {
+	var q queryJSON
+	if err := json.Unmarshal(data, &q); err != nil {
+		return err
+	}
+
+	*qb = *NewQuery()
+	for k, v := range q.Filters {
+		qb.filters[k] = v
+	}
+	for group, filters := range q.OrFilters {
+		qb.orFilters[group] = filters
+	}
+	qb.limit = q.Limit
+	qb.offset = q.Offset
+	qb.sort = q.Sort
+	qb.order = q.Order
+	qb.sortOrigin = q.SortOrigin
+
+	return nil
+}
+
+// ToData returns the template in a form that can be stored as document data
+func (t *QueryTemplate) ToData() (map[string]interface{}, error) {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query template: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to encode query template: %w", err)
+	}
+	return data, nil
+}
+
+// QueryTemplateFromDocument decodes a template stored with SaveQueryTemplate
+func QueryTemplateFromDocument(doc Document) (*QueryTemplate, error) {
+	raw, err := json.Marshal(doc.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode query template: %w", err)
+	}
+
+	var tpl QueryTemplate
+	if err := json.Unmarshal(raw, &tpl); err != nil {
+		return nil, fmt.Errorf("failed to decode query template: %w", err)
+	}
+	if tpl.Query == nil {
+		tpl.Query = NewQuery()
+	}
+	return &tpl, nil
+}
+
+// SaveQueryTemplate stores tpl as a document in collection
+func (c *Client) SaveQueryTemplate(ctx context.Context, collection string, tpl *QueryTemplate, opts ...CallOption) (*Document, error) {
+	data, err := tpl.ToData()
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateDocument(ctx, collection, data, opts...)
+}
+
+// LoadQueryTemplate fetches the template called name from collection
+func (c *Client) LoadQueryTemplate(ctx context.Context, collection, name string, opts ...CallOption) (*QueryTemplate, error) {
+	docs, err := c.ListDocuments(ctx, collection, NewQuery().Where("name", name).Limit(1), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("query template %q not found in %s", name, collection)
+	}
+	return QueryTemplateFromDocument(docs[0])
+}
+
+// ListDocumentsWithTemplate binds params to tpl and lists the matching documents
+func (c *Client) ListDocumentsWithTemplate(ctx context.Context, collection string, tpl *QueryTemplate, params map[string]interface{}, opts ...CallOption) ([]Document, error) {
+	query, err := tpl.Bind(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListDocuments(ctx, collection, query, opts...)
+}
@@ -0,0 +1,245 @@
+package cocobase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRefreshLeeway is how long before expiry the access token is refreshed
+const DefaultRefreshLeeway = 30 * time.Second
+
+// ErrNoRefreshToken is returned by RefreshSession when the server did not
+// issue a refresh token
+var ErrNoRefreshToken = errors.New("cocobase: no refresh token")
+
+// jwtExpiry reads the exp claim of a JWT. The signature is not verified;
+// the expiry is only used to decide when to refresh.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(exp), 0), true
+}
+
+// expiresAt returns when the access token expires, from expires_in or the
+// token's exp claim, or the zero time if unknown
+func (t TokenResponse) expiresAt(now time.Time) time.Time {
+	if t.ExpiresIn > 0 {
+		return now.Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	exp, _ := jwtExpiry(t.AccessToken)
+	return exp
+}
+
+// TokenExpiry returns when the client's access token expires, or the zero
+// time if unknown
+func (c *Client) TokenExpiry() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokenExpiry
+}
+
+// RefreshSession exchanges the refresh token for a new access token. Calls
+// refresh automatically shortly before expiry and after a 401, so this is
+// only needed to force a refresh.
+func (c *Client) RefreshSession(ctx context.Context, opts ...CallOption) error {
+	return c.refresh(ctx, c.GetToken(), opts)
+}
+
+// refresh renews the client's tokens unless they changed since stale was
+// read. Concurrent callers wait for a single refresh and then reuse its result.
+func (c *Client) refresh(ctx context.Context, stale string, opts []CallOption) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.mu.RLock()
+	token, refreshToken := c.token, c.refreshToken
+	c.mu.RUnlock()
+
+	if token != stale {
+		return nil
+	}
+	if refreshToken == "" {
+		return ErrNoRefreshToken
+	}
+
+	tokens, err := c.exchangeRefreshToken(ctx, refreshToken, opts)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusBadRequest) {
+			c.expireSession(token)
+		}
+		return err
+	}
+	if tokens.RefreshToken == "" {
+		tokens.RefreshToken = refreshToken
+	}
+
+	c.mu.Lock()
+	// Signed out or signed in again while refreshing
+	if c.token != token {
+		c.mu.Unlock()
+		return nil
+	}
+	err = c.storeTokensLocked(tokens.AccessToken, tokens.RefreshToken, tokens.expiresAt(time.Now()))
+	c.mu.Unlock()
+
+	c.logger.Info("token refreshed")
+	c.emitAuthEvent(AuthTokenRefreshed)
+
+	return err
+}
+
+// expireSession signs the client out after its refresh token was rejected,
+// unless the session changed since token was read
+func (c *Client) expireSession(token string) {
+	c.mu.Lock()
+	if c.token != token {
+		c.mu.Unlock()
+		return
+	}
+	c.user = nil
+	c.storeTokensLocked("", "", time.Time{})
+	c.mu.Unlock()
+
+	c.logger.Warn("refresh token rejected, session expired")
+	c.emitAuthEvent(AuthSessionExpired)
+}
+
+func (c *Client) exchangeRefreshToken(ctx context.Context, refreshToken string, opts []CallOption) (TokenResponse, error) {
+	body := map[string]string{"refresh_token": refreshToken}
+	resp, err := c.request(ctx, Operation{Name: OpAuthRefresh}, http.MethodPost, "/auth-collections/refresh", body, false, withoutAuth(opts)...)
+	if err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens TokenResponse
+	if err := c.decode(resp.Body, &tokens); err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if tokens.AccessToken == "" {
+		return TokenResponse{}, fmt.Errorf("failed to refresh token: no access token in response")
+	}
+
+	return tokens, nil
+}
+
+// refreshIfExpiring refreshes the client's token shortly before it expires.
+// A failed refresh is logged and the call goes ahead with the old token.
+func (c *Client) refreshIfExpiring(ctx context.Context, o *callOptions) {
+	if !c.autoRefresh || !c.usesClientToken(ctx, o) {
+		return
+	}
+
+	c.mu.RLock()
+	token, refreshToken, expiry := c.token, c.refreshToken, c.tokenExpiry
+	c.mu.RUnlock()
+
+	if token == "" || refreshToken == "" || expiry.IsZero() || time.Until(expiry) > c.refreshLeeway {
+		return
+	}
+
+	if err := c.refresh(ctx, token, nil); err != nil {
+		c.logger.Warn("token refresh failed", "error", err.Error())
+	}
+}
+
+// refreshAfterUnauthorized refreshes the client's token after the server
+// rejected it with 401, reporting whether the request should be sent again
+func (c *Client) refreshAfterUnauthorized(ctx context.Context, req *http.Request, resp *http.Response, o *callOptions) bool {
+	if resp.StatusCode != http.StatusUnauthorized || !c.autoRefresh || !c.usesClientToken(ctx, o) {
+		return false
+	}
+
+	sent := strings.TrimPrefix(req.Header.Get(HeaderAuthorization), "Bearer ")
+	if sent == "" {
+		return false
+	}
+
+	c.mu.RLock()
+	canRefresh := c.refreshToken != ""
+	c.mu.RUnlock()
+	if !canRefresh {
+		return false
+	}
+
+	if err := c.refresh(ctx, sent, nil); err != nil {
+		c.logger.Warn("token refresh failed", "error", err.Error())
+		return false
+	}
+	return true
+}
+
+// storeTokensLocked sets the client's tokens and persists them. c.mu must be
+// held.
+func (c *Client) storeTokensLocked(token, refreshToken string, expiry time.Time) error {
+	c.token = token
+	c.refreshToken = refreshToken
+	c.tokenExpiry = expiry
+	c.logger.Debug("auth token set", "authenticated", token != "")
+
+	if c.storage == nil {
+		return nil
+	}
+
+	var expiryValue string
+	if !expiry.IsZero() {
+		expiryValue = expiry.UTC().Format(time.RFC3339)
+	}
+
+	return errors.Join(
+		c.storeValue("cocobase-token", token),
+		c.storeValue("cocobase-refresh-token", refreshToken),
+		c.storeValue("cocobase-token-expiry", expiryValue),
+	)
+}
+
+// storeValue saves value under key, deleting the key when value is empty
+func (c *Client) storeValue(key, value string) error {
+	if value == "" {
+		err := c.storage.Delete(key)
+		c.logStorageError("delete", key, err)
+		return err
+	}
+	err := c.storage.Set(key, value)
+	c.logStorageError("set", key, err)
+	return err
+}
+
+// restoreTokensLocked loads the refresh token and expiry saved alongside the
+// access token. c.mu must be held.
+func (c *Client) restoreTokensLocked() {
+	c.refreshToken, _ = c.storage.Get("cocobase-refresh-token")
+	c.tokenExpiry = time.Time{}
+
+	if s, err := c.storage.Get("cocobase-token-expiry"); err == nil {
+		c.tokenExpiry, _ = time.Parse(time.RFC3339, s)
+	} else if exp, ok := jwtExpiry(c.token); ok {
+		c.tokenExpiry = exp
+	}
+}
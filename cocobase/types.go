@@ -1,7 +1,9 @@
 package cocobase
 
 import (
+	"log/slog"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
@@ -14,6 +16,8 @@ const (
 	ContentTypeJSON         = "application/json"
 	HeaderAPIKey           = "x-api-key"
 	HeaderAuthorization    = "Authorization"
+	HeaderUserAgent        = "User-Agent"
+	DefaultUserAgent       = "cocobase-go"
 )
 
 type Client struct {
@@ -24,6 +28,30 @@ type Client struct {
 	httpClient *http.Client
 	mu         sync.RWMutex
 	storage    Storage
+
+	clientSideGeo bool
+	searchIndexes map[string]*SearchIndex
+	roundTrip     RoundTripFunc
+	logger        *slog.Logger
+	breaker       *circuitBreaker
+	cache         *responseCache
+	userAgent     string
+	headers       http.Header
+	retry         *RetryConfig
+	dedupe        *dedupeStore
+
+	compressRequests     bool
+	compressionThreshold int
+	maxResponseSize      int64
+	codec                Codec
+
+	refreshToken  string
+	tokenExpiry   time.Time
+	refreshMu     sync.Mutex
+	autoRefresh   bool
+	refreshLeeway time.Duration
+	authListeners authListeners
+	mfaChallenge  *MFAChallenge
 }
 
 type Config struct {
@@ -31,6 +59,76 @@ type Config struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Storage    Storage
+
+	// Timeout is the overall timeout for each request (default DefaultTimeout).
+	// Ignored when HTTPClient is set.
+	Timeout time.Duration
+
+	// UserAgent is sent with every request (default DefaultUserAgent)
+	UserAgent string
+
+	// Headers are added to every request. API key, Authorization and
+	// Content-Type headers are ignored.
+	Headers http.Header
+
+	// Proxy sends requests through a proxy. Ignored when HTTPClient is set;
+	// otherwise the HTTP_PROXY/HTTPS_PROXY environment variables apply.
+	Proxy *url.URL
+
+	// ClientSideGeo evaluates geospatial filters and distance sorting
+	// locally instead of sending them to the server
+	ClientSideGeo bool
+
+	// Middleware wraps every HTTP request, the first entry being outermost
+	Middleware []Middleware
+
+	// Logger receives structured logs for requests, auth changes, realtime
+	// connections and storage failures. Secrets are redacted. Nil disables logging.
+	Logger *slog.Logger
+
+	// RateLimit paces outgoing requests; nil disables client-side limiting
+	RateLimit *RateLimitConfig
+
+	// CircuitBreaker fails requests fast with ErrCircuitOpen while the
+	// server is unhealthy; nil disables it
+	CircuitBreaker *CircuitBreakerConfig
+
+	// Cache caches document reads and revalidates them with ETag /
+	// Last-Modified; nil disables caching
+	Cache *CacheConfig
+
+	// CoalesceReads makes identical concurrent GET requests (same path,
+	// query and credentials) share a single round-trip
+	CoalesceReads bool
+
+	// Retry retries failed requests that are safe to repeat; nil disables retries
+	Retry *RetryConfig
+
+	// DedupeWindow is how long created documents are remembered by
+	// idempotency key, so a replayed create the server reports as a
+	// duplicate returns the original (default DefaultDedupeWindow, negative disables)
+	DedupeWindow time.Duration
+
+	// CompressRequests gzips request bodies of at least CompressionThreshold
+	// bytes (default DefaultCompressionThreshold)
+	CompressRequests     bool
+	CompressionThreshold int
+
+	// MaxResponseSize caps the decompressed size of response bodies; reading
+	// past it fails with ErrResponseTooLarge. Zero means unlimited.
+	MaxResponseSize int64
+
+	// Codec encodes requests and decodes responses (default JSONCodec, which
+	// decodes numbers as json.Number)
+	Codec Codec
+
+	// RefreshLeeway is how long before the access token expires it is
+	// refreshed (default DefaultRefreshLeeway)
+	RefreshLeeway time.Duration
+
+	// DisableTokenRefresh turns off refreshing the access token before it
+	// expires and after a 401
+	DisableTokenRefresh bool
 }
 
 type Storage interface {
@@ -48,16 +146,20 @@ type Document struct {
 }
 
 type AppUser struct {
-	ID        string                 `json:"id"`
-	Email     string                 `json:"email"`
-	Roles     []string               `json:"roles"`
-	Data      map[string]interface{} `json:"data"`
-	CreatedAt time.Time              `json:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at"`
+	ID            string                 `json:"id"`
+	Email         string                 `json:"email"`
+	EmailVerified bool                   `json:"email_verified"`
+	Roles         []string               `json:"roles"`
+	Data          map[string]interface{} `json:"data"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
 }
 
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// ExpiresIn is the access token's lifetime in seconds
+	ExpiresIn int64 `json:"expires_in,omitempty"`
 }
 
 type Connection struct {
@@ -0,0 +1,194 @@
+// Package otelcocobase instruments the Cocobase client with OpenTelemetry
+// tracing and metrics.
+//
+//	client := cocobase.NewClient(cocobase.Config{
+//		APIKey:     "your-api-key",
+//		Middleware: []cocobase.Middleware{otelcocobase.Middleware()},
+//	})
+package otelcocobase
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope used for spans and metrics
+const ScopeName = "github.com/lordace-coder/cocobase-go/otelcocobase"
+
+// Attribute keys set on spans and metrics
+const (
+	OperationKey  = attribute.Key("cocobase.operation")
+	CollectionKey = attribute.Key("cocobase.collection")
+	DocumentIDKey = attribute.Key("cocobase.document_id")
+	QueryKey      = attribute.Key("cocobase.query")
+	AttemptKey    = attribute.Key("cocobase.attempt")
+	EventKey      = attribute.Key("cocobase.event")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option configures the instrumentation
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider (default: the global provider)
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider (default: the global provider)
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators used to inject trace context into
+// outgoing requests (default: the global propagators)
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type instruments struct {
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+}
+
+func newInstruments(meter metric.Meter) *instruments {
+	inst := &instruments{}
+	// Instrument creation only fails for invalid names; the no-op
+	// instruments returned alongside the error are safe to use.
+	inst.duration, _ = meter.Float64Histogram("cocobase.client.operation.duration",
+		metric.WithDescription("Duration of Cocobase API calls"),
+		metric.WithUnit("s"))
+	inst.requests, _ = meter.Int64Counter("cocobase.client.operation.requests",
+		metric.WithDescription("Number of Cocobase API calls"),
+		metric.WithUnit("{request}"))
+	inst.errors, _ = meter.Int64Counter("cocobase.client.operation.errors",
+		metric.WithDescription("Number of failed Cocobase API calls"),
+		metric.WithUnit("{request}"))
+	return inst
+}
+
+// Middleware returns a cocobase.Middleware that starts a client span per
+// operation (e.g. "cocobase.documents.list"), injects the trace context into
+// the outgoing request and records duration, request and error metrics.
+func Middleware(opts ...Option) cocobase.Middleware {
+	cfg := newConfig(opts)
+	tracer := cfg.tracerProvider.Tracer(ScopeName)
+	inst := newInstruments(cfg.meterProvider.Meter(ScopeName))
+
+	return func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+		return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+			attrs := operationAttributes(op)
+
+			spanAttrs := append([]attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.URLPath(req.URL.Path),
+				semconv.ServerAddress(req.URL.Hostname()),
+				AttemptKey.Int(op.Attempt),
+			}, attrs...)
+			if op.DocumentID != "" {
+				spanAttrs = append(spanAttrs, DocumentIDKey.String(op.DocumentID))
+			}
+			if req.URL.RawQuery != "" {
+				spanAttrs = append(spanAttrs, QueryKey.String(req.URL.RawQuery))
+			}
+
+			ctx, span := tracer.Start(req.Context(), "cocobase."+op.Name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(spanAttrs...))
+			defer span.End()
+
+			req = req.WithContext(ctx)
+			cfg.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			start := time.Now()
+			resp, err := next(req, op)
+			elapsed := time.Since(start).Seconds()
+
+			failed := false
+			switch {
+			case err != nil:
+				failed = true
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			default:
+				span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+				attrs = append(attrs, semconv.HTTPResponseStatusCode(resp.StatusCode))
+				if resp.StatusCode >= 400 {
+					failed = true
+					span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+				}
+			}
+
+			set := metric.WithAttributes(attrs...)
+			inst.duration.Record(ctx, elapsed, set)
+			inst.requests.Add(ctx, 1, set)
+			if failed {
+				inst.errors.Add(ctx, 1, set)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// operationAttributes returns the low-cardinality attributes shared by spans
+// and metrics; document IDs are only recorded on spans
+func operationAttributes(op cocobase.Operation) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{OperationKey.String(op.Name)}
+	if op.Collection != "" {
+		attrs = append(attrs, CollectionKey.String(op.Collection))
+	}
+	return attrs
+}
+
+// WrapEventHandler counts realtime events delivered to handler, for use with
+// Client.WatchCollection. The counter is "cocobase.realtime.events" with the
+// collection and event type as attributes.
+func WrapEventHandler(collection string, handler func(cocobase.Event), opts ...Option) func(cocobase.Event) {
+	cfg := newConfig(opts)
+	meter := cfg.meterProvider.Meter(ScopeName)
+	events, _ := meter.Int64Counter("cocobase.realtime.events",
+		metric.WithDescription("Number of realtime events received"),
+		metric.WithUnit("{event}"))
+
+	return func(event cocobase.Event) {
+		events.Add(context.Background(), 1, metric.WithAttributes(
+			CollectionKey.String(collection),
+			EventKey.String(event.Event),
+		))
+		handler(event)
+	}
+}
@@ -0,0 +1,178 @@
+package otelcocobase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"github.com/lordace-coder/cocobase-go/otelcocobase"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type testEnv struct {
+	client  *cocobase.Client
+	spans   *tracetest.InMemoryExporter
+	reader  *sdkmetric.ManualReader
+	headers chan http.Header
+}
+
+func setup(t *testing.T) *testEnv {
+	t.Helper()
+
+	headers := make(chan http.Header, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		switch {
+		case r.URL.Path == "/collections/users/documents/missing":
+			http.Error(w, "not found", http.StatusNotFound)
+		case r.URL.Path == "/collections/users/documents":
+			json.NewEncoder(w).Encode([]cocobase.Document{})
+		default:
+			json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	spans := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Middleware: []cocobase.Middleware{otelcocobase.Middleware(
+			otelcocobase.WithTracerProvider(tp),
+			otelcocobase.WithMeterProvider(mp),
+			otelcocobase.WithPropagators(propagation.TraceContext{}),
+		)},
+	})
+
+	return &testEnv{client: client, spans: spans, reader: reader, headers: headers}
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestMiddlewareCreatesSpans(t *testing.T) {
+	env := setup(t)
+
+	query := cocobase.NewQuery().Where("status", "active")
+	if _, err := env.client.ListDocuments(context.Background(), "users", query); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := env.spans.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "cocobase.documents.list" {
+		t.Errorf("Expected span name cocobase.documents.list, got %s", span.Name)
+	}
+	if v, _ := attr(span.Attributes, otelcocobase.CollectionKey); v.AsString() != "users" {
+		t.Errorf("Expected collection attribute, got %v", span.Attributes)
+	}
+	if v, _ := attr(span.Attributes, otelcocobase.QueryKey); v.AsString() != "status=active" {
+		t.Errorf("Expected query attribute, got %v", span.Attributes)
+	}
+	if v, _ := attr(span.Attributes, "http.response.status_code"); v.AsInt64() != 200 {
+		t.Errorf("Expected status code attribute, got %v", span.Attributes)
+	}
+
+	header := <-env.headers
+	if header.Get("Traceparent") == "" {
+		t.Errorf("Expected traceparent header to be propagated")
+	}
+}
+
+func TestMiddlewareRecordsErrors(t *testing.T) {
+	env := setup(t)
+
+	if _, err := env.client.GetDocument(context.Background(), "users", "missing"); err == nil {
+		t.Fatalf("Expected error")
+	}
+
+	spans := env.spans.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected error status, got %v", spans[0].Status)
+	}
+	if v, _ := attr(spans[0].Attributes, otelcocobase.DocumentIDKey); v.AsString() != "missing" {
+		t.Errorf("Expected document id attribute, got %v", spans[0].Attributes)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := env.reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	found := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+			if m.Name == "cocobase.client.operation.errors" {
+				sum := m.Data.(metricdata.Sum[int64])
+				if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1 {
+					t.Errorf("Expected 1 error, got %+v", sum.DataPoints)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"cocobase.client.operation.duration", "cocobase.client.operation.requests", "cocobase.client.operation.errors"} {
+		if !found[name] {
+			t.Errorf("Expected metric %s, got %v", name, found)
+		}
+	}
+}
+
+func TestWrapEventHandler(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	received := 0
+	handler := otelcocobase.WrapEventHandler("users", func(cocobase.Event) { received++ },
+		otelcocobase.WithMeterProvider(mp))
+
+	handler(cocobase.Event{Event: "create"})
+	handler(cocobase.Event{Event: "create"})
+	handler(cocobase.Event{Event: "delete"})
+
+	if received != 3 {
+		t.Errorf("Expected handler to receive 3 events, got %d", received)
+	}
+
+	var rm metricdata.ResourceMetrics
+	reader.Collect(context.Background(), &rm)
+
+	total := int64(0)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "cocobase.realtime.events" {
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					total += dp.Value
+				}
+			}
+		}
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 counted events, got %d", total)
+	}
+}
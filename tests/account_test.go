@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+// accountServer fakes the password and email verification endpoints for a
+// single user, alice@example.com
+type accountServer struct {
+	mu       sync.Mutex
+	password string
+	verified bool
+	logins   int
+	sent     []string
+	URL      string
+}
+
+func newAccountServer(t *testing.T) *accountServer {
+	t.Helper()
+	s := &accountServer{password: "old-password"}
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		token := strings.TrimPrefix(r.Header.Get(cocobase.HeaderAuthorization), "Bearer ")
+
+		switch r.URL.Path {
+		case "/auth-collections/login":
+			if body["email"] != "alice@example.com" || body["password"] != s.password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			s.logins++
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "token-" + s.password})
+		case "/auth-collections/user":
+			if token == "bob-token" && r.Method == http.MethodGet {
+				json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user2", Email: "bob@example.com"})
+				return
+			}
+			if token != "token-"+s.password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Method == http.MethodPatch {
+				s.password = body["password"]
+			}
+			json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1", Email: "alice@example.com", EmailVerified: s.verified})
+		case "/auth-collections/forgot-password":
+			s.sent = append(s.sent, "reset:"+body["email"])
+		case "/auth-collections/reset-password", "/auth-collections/verify-email":
+			switch body["token"] {
+			case "good":
+				if r.URL.Path == "/auth-collections/reset-password" {
+					s.password = body["password"]
+					return
+				}
+				s.verified = true
+				json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1", EmailVerified: true})
+			case "stale":
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"detail":"Token has expired"}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"detail":"Invalid token"}`))
+			}
+		case "/auth-collections/verify-email/send":
+			if token == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			s.sent = append(s.sent, "verify:"+token)
+		}
+	})
+	s.URL = server.URL
+	return s
+}
+
+func TestPasswordReset(t *testing.T) {
+	server := newAccountServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(server.sent) != 1 || server.sent[0] != "reset:alice@example.com" {
+		t.Errorf("Expected a reset email, got %v", server.sent)
+	}
+
+	err := client.ConfirmPasswordReset(ctx, "stale", "new-password")
+	var apiErr *cocobase.APIError
+	if !errors.Is(err, cocobase.ErrTokenExpired) || !errors.As(err, &apiErr) {
+		t.Errorf("Expected ErrTokenExpired wrapping an APIError, got %v", err)
+	}
+	if err := client.ConfirmPasswordReset(ctx, "unknown", "new-password"); !errors.Is(err, cocobase.ErrTokenInvalid) {
+		t.Errorf("Expected ErrTokenInvalid, got %v", err)
+	}
+
+	if err := client.ConfirmPasswordReset(ctx, "good", "new-password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.Login(ctx, "alice@example.com", "new-password"); err != nil {
+		t.Errorf("Expected to sign in with the new password, got %v", err)
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	server := newAccountServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.SendVerificationEmail(ctx); err == nil {
+		t.Error("Expected an error when not signed in")
+	}
+
+	if err := client.Login(ctx, "alice@example.com", "old-password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var updated *cocobase.AppUser
+	client.OnAuthStateChange(func(e cocobase.AuthEvent) {
+		if e.Type == cocobase.AuthUserUpdated {
+			updated = e.User
+		}
+	})
+
+	if err := client.SendVerificationEmail(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(server.sent) != 1 || server.sent[0] != "verify:token-old-password" {
+		t.Errorf("Expected a verification email for the signed-in user, got %v", server.sent)
+	}
+
+	if err := client.VerifyEmail(ctx, "stale"); !errors.Is(err, cocobase.ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+	if err := client.VerifyEmail(ctx, "good"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if updated == nil || !updated.EmailVerified {
+		t.Errorf("Expected the signed-in user to be marked verified, got %+v", updated)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	server := newAccountServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "old-password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := client.ChangePassword(ctx, "wrong", "new-password"); !errors.Is(err, cocobase.ErrInvalidCredentials) {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+	if server.password != "old-password" {
+		t.Fatal("Expected the password to be unchanged")
+	}
+
+	if err := client.ChangePassword(ctx, "old-password", "new-password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if server.password != "new-password" {
+		t.Errorf("Expected the password to be changed, got %q", server.password)
+	}
+	if got := client.GetToken(); got != "token-new-password" {
+		t.Errorf("Expected a session issued for the new password, got %q", got)
+	}
+	if _, err := client.GetCurrentUser(ctx); err != nil {
+		t.Errorf("Expected the client to stay signed in, got %v", err)
+	}
+}
+
+func TestChangePasswordForAnotherUser(t *testing.T) {
+	server := newAccountServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	client.SetToken("bob-token")
+	if _, err := client.GetCurrentUser(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alice, err := client.LoginSession(ctx, "alice@example.com", "old-password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := client.ChangePassword(cocobase.WithUserToken(ctx, alice.Token), "old-password", "new-password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if server.password != "new-password" {
+		t.Errorf("Expected alice's password to be changed, got %q", server.password)
+	}
+
+	if got := client.GetToken(); got != "bob-token" {
+		t.Errorf("Expected the client session to be unchanged, got %q", got)
+	}
+	if user, err := client.GetCurrentUser(ctx); err != nil || user.ID != "user2" {
+		t.Errorf("Expected the client to stay signed in as bob, got %+v, %v", user, err)
+	}
+}
+
+func TestSessionAccountCalls(t *testing.T) {
+	server := newAccountServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	client.SetToken("bob-token")
+	ctx := context.Background()
+
+	alice, err := client.LoginSession(ctx, "alice@example.com", "old-password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := alice.SendVerificationEmail(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(server.sent) != 1 || server.sent[0] != "verify:token-old-password" {
+		t.Errorf("Expected the session's token to be sent, got %v", server.sent)
+	}
+
+	if err := alice.ChangePassword(ctx, "old-password", "new-password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if alice.Token != "token-new-password" {
+		t.Errorf("Expected the session to switch to the new token, got %q", alice.Token)
+	}
+	if got := client.GetToken(); got != "bob-token" {
+		t.Errorf("Expected the client session to be unchanged, got %q", got)
+	}
+}
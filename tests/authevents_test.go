@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"github.com/lordace-coder/cocobase-go/storage"
+)
+
+// recordEvents subscribes to client's auth events and returns the types seen
+func recordEvents(client *cocobase.Client) (func() []string, func()) {
+	var mu sync.Mutex
+	var events []string
+	unsubscribe := client.OnAuthStateChange(func(e cocobase.AuthEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e.Type.String())
+	})
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), events...)
+	}, unsubscribe
+}
+
+func TestAuthStateChangeEvents(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	store := storage.NewMemoryStorage()
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	ctx := context.Background()
+	events, unsubscribe := recordEvents(client)
+
+	var signedInUser *cocobase.AppUser
+	client.OnAuthStateChange(func(e cocobase.AuthEvent) {
+		if e.Type == cocobase.AuthSignedIn {
+			signedInUser = e.User
+		}
+	})
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if signedInUser == nil || signedInUser.ID != "user1" {
+		t.Errorf("Expected the signed-in user with the event, got %+v", signedInUser)
+	}
+	if err := client.RefreshSession(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.Logout(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"signed-in", "token-refreshed", "signed-out"}
+	if got := events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	unsubscribe()
+	client.SetToken("token")
+	if got := events(); len(got) != len(want) {
+		t.Errorf("Expected no events after unsubscribing, got %v", got)
+	}
+}
+
+func TestAuthStateRestoredByInitAuth(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+
+	first := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	if err := first.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	events, _ := recordEvents(client)
+	if err := client.InitAuth(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := events(); !reflect.DeepEqual(got, []string{"signed-in"}) {
+		t.Errorf("Expected signed-in on restore, got %v", got)
+	}
+}
+
+func TestAuthStateSessionExpired(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	events, _ := recordEvents(client)
+
+	server.mu.Lock()
+	server.rejectAll = true
+	server.mu.Unlock()
+	server.revoke()
+
+	if _, err := client.GetDocument(ctx, "users", "doc1"); err == nil {
+		t.Fatal("Expected an error once the session expired")
+	}
+
+	if got := events(); !reflect.DeepEqual(got, []string{"session-expired"}) {
+		t.Errorf("Expected session-expired, got %v", got)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client to be signed out")
+	}
+}
+
+func TestAuthStateSetToken(t *testing.T) {
+	client := cocobase.NewClient(cocobase.Config{})
+	events, _ := recordEvents(client)
+
+	client.SetToken("first")
+	client.SetToken("first")
+	client.SetToken("second")
+	client.SetToken("")
+
+	want := []string{"signed-in", "token-refreshed", "signed-out"}
+	if got := events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestCacheServesRepeatedReads(t *testing.T) {
+	var hits int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		json.NewEncoder(w).Encode([]cocobase.Document{{ID: "doc1"}})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Cache:   &cocobase.CacheConfig{TTL: time.Minute},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		docs, err := client.ListDocuments(ctx, "users", cocobase.NewQuery().Where("status", "active").Limit(5))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(docs) != 1 || docs[0].ID != "doc1" {
+			t.Fatalf("Unexpected documents: %+v", docs)
+		}
+	}
+
+	// Same query with filters added in a different order
+	if _, err := client.ListDocuments(ctx, "users", cocobase.NewQuery().Limit(5).Where("status", "active")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", n)
+	}
+}
+
+func TestCacheRevalidatesWithETag(t *testing.T) {
+	var hits, notModified int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1", Data: map[string]interface{}{"name": "Alice"}})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Cache:   &cocobase.CacheConfig{},
+	})
+
+	for i := 0; i < 3; i++ {
+		doc, err := client.GetDocument(context.Background(), "users", "doc1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if doc.Data["name"] != "Alice" {
+			t.Fatalf("Expected cached body, got %+v", doc)
+		}
+	}
+
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("Expected every call to revalidate, got %d requests", n)
+	}
+	if n := atomic.LoadInt32(&notModified); n != 2 {
+		t.Errorf("Expected 2 conditional requests, got %d", n)
+	}
+}
+
+func TestCacheInvalidatedByWrites(t *testing.T) {
+	var hits int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&hits, 1)
+		}
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Cache:   &cocobase.CacheConfig{TTL: time.Minute},
+	})
+	ctx := context.Background()
+
+	client.GetDocument(ctx, "users", "doc1")
+	client.GetDocument(ctx, "users", "doc1")
+	if _, err := client.UpdateDocument(ctx, "users", "doc1", map[string]interface{}{"name": "Bob"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.GetDocument(ctx, "users", "doc1")
+
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("Expected update to invalidate the cache, got %d reads", n)
+	}
+}
+
+func TestCachePerCallOptions(t *testing.T) {
+	var hits int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Cache:   &cocobase.CacheConfig{TTL: time.Minute},
+	})
+	ctx := context.Background()
+
+	client.GetDocument(ctx, "users", "doc1")
+	client.GetDocument(ctx, "users", "doc1", cocobase.WithoutCache())
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("Expected WithoutCache to reach the server, got %d requests", n)
+	}
+
+	client.GetDocument(ctx, "users", "doc1", cocobase.WithCacheTTL(0))
+	if n := atomic.LoadInt32(&hits); n != 3 {
+		t.Errorf("Expected zero TTL to skip the fresh entry, got %d requests", n)
+	}
+}
+
+func TestCacheKeyedByAuthIdentity(t *testing.T) {
+	var hits int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Cache:   &cocobase.CacheConfig{TTL: time.Minute},
+	})
+	ctx := context.Background()
+
+	client.SetToken("alice-token")
+	client.GetDocument(ctx, "users", "doc1")
+	client.SetToken("bob-token")
+	client.GetDocument(ctx, "users", "doc1")
+
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("Expected separate cache entries per user, got %d requests", n)
+	}
+}
+
+func TestLRUCacheEvictsOldest(t *testing.T) {
+	cache := cocobase.NewLRUCache(2)
+	cache.Set("a", &cocobase.CachedResponse{Collection: "users"})
+	cache.Set("b", &cocobase.CachedResponse{Collection: "posts"})
+	cache.Get("a")
+	cache.Set("c", &cocobase.CachedResponse{Collection: "users"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+
+	cache.Invalidate("users")
+	if cache.Len() != 0 {
+		t.Errorf("Expected Invalidate to remove users entries, %d left", cache.Len())
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func headerServer(t *testing.T) (*cocobase.Client, chan http.Header) {
+	t.Helper()
+	headers := make(chan http.Header, 10)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, APIKey: "key-123"})
+	client.SetToken("client-token")
+	return client, headers
+}
+
+func TestCallHeaderAndIdempotencyKey(t *testing.T) {
+	client, headers := headerServer(t)
+	ctx := context.Background()
+
+	_, err := client.CreateDocument(ctx, "users", map[string]interface{}{"name": "Alice"},
+		cocobase.WithCallHeader("X-Trace-ID", "trace-1"),
+		cocobase.WithIdempotencyKey("create-alice"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	h := <-headers
+	if got := h.Get("X-Trace-ID"); got != "trace-1" {
+		t.Errorf("Expected trace header, got %q", got)
+	}
+	if got := h.Get(cocobase.HeaderIdempotencyKey); got != "create-alice" {
+		t.Errorf("Expected idempotency key, got %q", got)
+	}
+
+	client.GetDocument(ctx, "users", "doc1")
+	if got := (<-headers).Get("X-Trace-ID"); got != "" {
+		t.Errorf("Expected call header not to leak into later calls, got %q", got)
+	}
+}
+
+func TestBearerTokenOverride(t *testing.T) {
+	client, headers := headerServer(t)
+	ctx := context.Background()
+
+	client.GetDocument(ctx, "users", "doc1", cocobase.WithBearerToken("other-token"))
+	if got := (<-headers).Get(cocobase.HeaderAuthorization); got != "Bearer other-token" {
+		t.Errorf("Expected overridden token, got %q", got)
+	}
+
+	client.GetDocument(ctx, "users", "doc1", cocobase.WithoutAuth())
+	if got := (<-headers).Get(cocobase.HeaderAuthorization); got != "" {
+		t.Errorf("Expected no Authorization header, got %q", got)
+	}
+
+	client.GetDocument(ctx, "users", "doc1")
+	if got := (<-headers).Get(cocobase.HeaderAuthorization); got != "Bearer client-token" {
+		t.Errorf("Expected client token to be unchanged, got %q", got)
+	}
+	if client.GetToken() != "client-token" {
+		t.Errorf("Expected client token to be unchanged, got %q", client.GetToken())
+	}
+}
+
+func TestGetCurrentUserWithBearerToken(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(cocobase.HeaderAuthorization) != "Bearer other-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user-2", Email: "bob@example.com"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	user, err := client.GetCurrentUser(context.Background(), cocobase.WithBearerToken("other-token"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != "user-2" {
+		t.Errorf("Expected user-2, got %s", user.ID)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client to stay signed out")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	start := time.Now()
+	_, err := client.GetDocument(context.Background(), "users", "doc1", cocobase.WithCallTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the call to time out quickly, took %s", elapsed)
+	}
+}
@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	var hits int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		CircuitBreaker: &cocobase.CircuitBreakerConfig{
+			FailureRatio: 0.5,
+			MinRequests:  4,
+			OpenTimeout:  50 * time.Millisecond,
+		},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.GetDocument(ctx, "users", "doc1"); errors.Is(err, cocobase.ErrCircuitOpen) {
+			t.Fatalf("Breaker opened early on request %d", i+1)
+		}
+	}
+
+	if state := client.CircuitState(); state != cocobase.CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", state)
+	}
+
+	_, err := client.GetDocument(ctx, "users", "doc1")
+	if !errors.Is(err, cocobase.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 4 {
+		t.Errorf("Expected open circuit not to reach the server, got %d hits", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if state := client.CircuitState(); state != cocobase.CircuitHalfOpen {
+		t.Fatalf("Expected half-open circuit, got %s", state)
+	}
+
+	healthy.Store(true)
+	if _, err := client.GetDocument(ctx, "users", "doc1"); err != nil {
+		t.Fatalf("Expected probe to succeed, got %v", err)
+	}
+	if state := client.CircuitState(); state != cocobase.CircuitClosed {
+		t.Errorf("Expected closed circuit after successful probe, got %s", state)
+	}
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	changes := make(chan cocobase.CircuitState, 10)
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		CircuitBreaker: &cocobase.CircuitBreakerConfig{
+			MinRequests: 1,
+			OpenTimeout: 20 * time.Millisecond,
+			OnStateChange: func(from, to cocobase.CircuitState) {
+				changes <- to
+			},
+		},
+	})
+	ctx := context.Background()
+
+	client.GetDocument(ctx, "users", "doc1")
+	time.Sleep(30 * time.Millisecond)
+	client.GetDocument(ctx, "users", "doc1")
+
+	if state := client.CircuitState(); state != cocobase.CircuitOpen {
+		t.Fatalf("Expected failed probe to reopen the circuit, got %s", state)
+	}
+
+	want := []cocobase.CircuitState{cocobase.CircuitOpen, cocobase.CircuitHalfOpen, cocobase.CircuitOpen}
+	for i, w := range want {
+		select {
+		case got := <-changes:
+			if got != w {
+				t.Errorf("Transition %d: expected %s, got %s", i, w, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for transition %d", i)
+		}
+	}
+}
+
+func TestCircuitBreakerStateChangesInOrder(t *testing.T) {
+	var healthy atomic.Bool
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	var mu sync.Mutex
+	var changes []string
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		CircuitBreaker: &cocobase.CircuitBreakerConfig{
+			MinRequests: 1,
+			OpenTimeout: time.Millisecond,
+			OnStateChange: func(from, to cocobase.CircuitState) {
+				// A slow first callback must not let later ones overtake it
+				if from == cocobase.CircuitClosed {
+					time.Sleep(20 * time.Millisecond)
+				}
+				mu.Lock()
+				changes = append(changes, from.String()+"->"+to.String())
+				mu.Unlock()
+			},
+		},
+	})
+	ctx := context.Background()
+
+	go client.GetDocument(ctx, "users", "doc1")
+	time.Sleep(5 * time.Millisecond)
+	healthy.Store(true)
+	client.GetDocument(ctx, "users", "doc1")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(changes)
+		mu.Unlock()
+		if n >= 3 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, changes)
+		}
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:        server.URL,
+		CircuitBreaker: &cocobase.CircuitBreakerConfig{MinRequests: 1},
+	})
+
+	for i := 0; i < 5; i++ {
+		client.GetDocument(context.Background(), "users", "doc1")
+	}
+
+	if state := client.CircuitState(); state != cocobase.CircuitClosed {
+		t.Errorf("Expected 404s to leave the circuit closed, got %s", state)
+	}
+}
+
+func TestCircuitStateWithoutBreaker(t *testing.T) {
+	client := cocobase.NewClient(cocobase.Config{})
+	if state := client.CircuitState(); state != cocobase.CircuitClosed {
+		t.Errorf("Expected closed circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresRateLimitWaits(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:        server.URL,
+		RateLimit:      &cocobase.RateLimitConfig{Global: cocobase.RateLimit{RequestsPerSecond: 1, Burst: 1}},
+		CircuitBreaker: &cocobase.CircuitBreakerConfig{MinRequests: 4},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetDocument(context.Background(), "users", "doc1", cocobase.WithCallTimeout(50*time.Millisecond))
+		}()
+	}
+	wg.Wait()
+
+	if state := client.CircuitState(); state != cocobase.CircuitClosed {
+		t.Errorf("Expected local rate limit timeouts to leave the circuit closed, got %s", state)
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestCoalesceIdenticalReads(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "global", Data: map[string]interface{}{"theme": "dark"}})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, CoalesceReads: true})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc, err := client.GetDocument(context.Background(), "config", "global")
+			if err == nil && doc.Data["theme"] != "dark" {
+				err = errors.New("unexpected document data")
+			}
+			errs <- err
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", n)
+	}
+}
+
+func TestCoalesceCancelledCallerDoesNotAffectOthers(t *testing.T) {
+	release := make(chan struct{})
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "global"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, CoalesceReads: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := client.GetDocument(ctx, "config", "global")
+		cancelled <- err
+	}()
+
+	result := make(chan error, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, err := client.GetDocument(context.Background(), "config", "global")
+		result <- err
+	}()
+
+	time.Sleep(40 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancelled caller to get context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("Expected remaining caller to succeed, got %v", err)
+	}
+}
+
+func TestCoalesceCancelsWhenAllCallersLeave(t *testing.T) {
+	aborted := make(chan struct{})
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(aborted)
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, CoalesceReads: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetDocument(ctx, "config", "global"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("Expected the shared request to be cancelled")
+	}
+}
+
+func TestCoalesceKeepsDifferentRequestsApart(t *testing.T) {
+	var hits int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(30 * time.Millisecond)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "global"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, CoalesceReads: true})
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			client.GetDocument(context.Background(), "config", id)
+		}(id)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("Expected different documents to use separate requests, got %d", n)
+	}
+}
+
+func TestCoalesceKeepsRevalidationsApart(t *testing.T) {
+	revalidating := make(chan struct{})
+	release := make(chan struct{})
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			close(revalidating)
+			<-release
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "global"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:       server.URL,
+		Cache:         &cocobase.CacheConfig{},
+		CoalesceReads: true,
+	})
+	ctx := context.Background()
+
+	// Store an entry that must be revalidated on every read
+	if _, err := client.GetDocument(ctx, "config", "global"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	revalidated := make(chan error, 1)
+	go func() {
+		_, err := client.GetDocument(ctx, "config", "global")
+		revalidated <- err
+	}()
+	<-revalidating
+
+	// A plain read must not join the pending conditional request and get its
+	// empty 304
+	doc, err := client.GetDocument(ctx, "config", "global", cocobase.WithoutCache(), cocobase.WithCallTimeout(2*time.Second))
+	if err != nil || doc.ID != "global" {
+		t.Errorf("Expected the uncached read to get the document, got %+v, %v", doc, err)
+	}
+
+	close(release)
+	if err := <-revalidated; err != nil {
+		t.Errorf("Unexpected error from the revalidation: %v", err)
+	}
+}
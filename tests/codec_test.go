@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestDefaultCodecPreservesLargeNumbers(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"doc1","data":{"account":9007199254740993,"balance":12345678901234.56}}`))
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	doc, err := client.GetDocument(context.Background(), "accounts", "doc1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	account, ok := doc.Data["account"].(json.Number)
+	if !ok {
+		t.Fatalf("Expected json.Number, got %T", doc.Data["account"])
+	}
+	if n, _ := account.Int64(); n != 9007199254740993 {
+		t.Errorf("Expected exact integer, got %d", n)
+	}
+	if balance := doc.Data["balance"].(json.Number).String(); balance != "12345678901234.56" {
+		t.Errorf("Expected exact decimal, got %s", balance)
+	}
+}
+
+func TestJSONCodecUseFloat64(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"doc1","data":{"age":30}}`))
+	})
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Codec:   cocobase.JSONCodec{UseFloat64: true},
+	})
+
+	doc, err := client.GetDocument(context.Background(), "users", "doc1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := doc.Data["age"].(float64); !ok {
+		t.Errorf("Expected float64, got %T", doc.Data["age"])
+	}
+}
+
+// upperCodec is a toy codec that upper-cases JSON on the wire
+type upperCodec struct{}
+
+func (upperCodec) ContentType() string { return "application/x-upper-json" }
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return []byte(strings.ToUpper(string(data))), err
+}
+
+func (upperCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(strings.ToLower(string(data))), v)
+}
+
+func TestCustomCodec(t *testing.T) {
+	var contentType, accept, body string
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`[{"ID":"DOC1"},{"ID":"DOC2"}]`))
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Codec: upperCodec{}})
+	ctx := context.Background()
+
+	client.CreateDocument(ctx, "users", map[string]interface{}{"name": "alice"})
+	if contentType != "application/x-upper-json" || accept != "application/x-upper-json" {
+		t.Errorf("Expected codec content type, got Content-Type %q, Accept %q", contentType, accept)
+	}
+	if !strings.Contains(body, `"NAME":"ALICE"`) {
+		t.Errorf("Expected body encoded by the codec, got %s", body)
+	}
+
+	var ids []string
+	err := client.StreamDocuments(ctx, "users", nil, func(doc cocobase.Document) error {
+		ids = append(ids, doc.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "doc1" {
+		t.Errorf("Expected documents decoded by the codec, got %v", ids)
+	}
+}
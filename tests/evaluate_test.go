@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func sampleDocs() []cocobase.Document {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []cocobase.Document{
+		{
+			ID:        "1",
+			CreatedAt: base,
+			Data: map[string]interface{}{
+				"name":     "John Smith",
+				"age":      float64(30),
+				"status":   "active",
+				"tags":     []interface{}{"go", "rust"},
+				"nickname": nil,
+				"address":  map[string]interface{}{"city": "Lagos"},
+			},
+		},
+		{
+			ID:        "2",
+			CreatedAt: base.Add(time.Hour),
+			Data: map[string]interface{}{
+				"name":   "jane doe",
+				"age":    float64(25),
+				"status": "banned",
+				"tags":   []interface{}{"python"},
+			},
+		},
+		{
+			ID:        "3",
+			CreatedAt: base.Add(2 * time.Hour),
+			Data: map[string]interface{}{
+				"name":   "Alice",
+				"age":    float64(41),
+				"status": "active",
+				"tags":   []interface{}{"go", "python", "rust"},
+			},
+		},
+	}
+}
+
+func ids(docs []cocobase.Document) []string {
+	result := make([]string, len(docs))
+	for i, doc := range docs {
+		result[i] = doc.ID
+	}
+	return result
+}
+
+func assertIDs(t *testing.T, got []cocobase.Document, want ...string) {
+	t.Helper()
+	gotIDs := ids(got)
+	if len(gotIDs) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, gotIDs)
+	}
+	for i := range want {
+		if gotIDs[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, gotIDs)
+		}
+	}
+}
+
+func TestEvaluateComparison(t *testing.T) {
+	docs := sampleDocs()
+
+	assertIDs(t, cocobase.NewQuery().Where("status", "active").Apply(docs), "1", "3")
+	assertIDs(t, cocobase.NewQuery().GreaterThan("age", 29).Apply(docs), "1", "3")
+	assertIDs(t, cocobase.NewQuery().Between("age", 25, 30).Apply(docs), "1", "2")
+	assertIDs(t, cocobase.NewQuery().NotIn("status", "banned").Apply(docs), "1", "3")
+	assertIDs(t, cocobase.NewQuery().Where("address.city", "Lagos").Apply(docs), "1")
+}
+
+func TestEvaluateStringOperators(t *testing.T) {
+	docs := sampleDocs()
+
+	assertIDs(t, cocobase.NewQuery().Contains("name", "JOHN").Apply(docs), "1")
+	assertIDs(t, cocobase.NewQuery().ContainsCaseSensitive("name", "JOHN").Apply(docs))
+	assertIDs(t, cocobase.NewQuery().StartsWithCaseSensitive("name", "j").Apply(docs), "2")
+	assertIDs(t, cocobase.NewQuery().Matches("name", "^[A-Z][a-z]+$").Apply(docs), "3")
+	assertIDs(t, cocobase.NewQuery().Search("doe", "name", "status").Apply(docs), "2")
+}
+
+func TestEvaluateInvalidRegex(t *testing.T) {
+	docs := sampleDocs()
+
+	valid := cocobase.NewQuery().Matches("name", "^J")
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	invalid := cocobase.NewQuery().Matches("name", "([")
+	assertIDs(t, invalid.Apply(docs))
+	if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), "name_regex") {
+		t.Errorf("Expected the invalid pattern to be reported, got %v", err)
+	}
+}
+
+func TestEvaluateArrayOperators(t *testing.T) {
+	docs := sampleDocs()
+
+	assertIDs(t, cocobase.NewQuery().ArrayContains("tags", "go").Apply(docs), "1", "3")
+	assertIDs(t, cocobase.NewQuery().ArrayContainsAny("tags", "python", "java").Apply(docs), "2", "3")
+	assertIDs(t, cocobase.NewQuery().ArrayContainsAll("tags", "go", "rust").Apply(docs), "1", "3")
+	assertIDs(t, cocobase.NewQuery().SizeEquals("tags", 1).Apply(docs), "2")
+}
+
+func TestEvaluateExistsVersusNull(t *testing.T) {
+	docs := sampleDocs()
+
+	assertIDs(t, cocobase.NewQuery().Exists("nickname").Apply(docs), "1")
+	assertIDs(t, cocobase.NewQuery().IsNull("nickname").Apply(docs), "1", "2", "3")
+	assertIDs(t, cocobase.NewQuery().NotExists("nickname").Apply(docs), "2", "3")
+}
+
+func TestEvaluateOrGroups(t *testing.T) {
+	docs := sampleDocs()
+
+	query := cocobase.NewQuery().
+		OrGroup("age").
+		LessThan("age", 26).
+		GreaterThan("age", 40).
+		Done().
+		OrGroup("tags").
+		ArrayContains("tags", "python").
+		Done()
+
+	assertIDs(t, query.Apply(docs), "2", "3")
+}
+
+func TestApplySortAndPagination(t *testing.T) {
+	docs := sampleDocs()
+
+	assertIDs(t, cocobase.NewQuery().OrderByDesc("age").Apply(docs), "3", "1", "2")
+	assertIDs(t, cocobase.NewQuery().Recent().Limit(2).Apply(docs), "3", "2")
+	assertIDs(t, cocobase.NewQuery().OrderBy("age").Page(2, 2).Apply(docs), "3")
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestExplainDescribesQuery(t *testing.T) {
+	explanation := cocobase.NewQuery().
+		Where("status", "active").
+		GreaterThanOrEqual("age", 18).
+		OrGroup("tier").
+		Where("isPremium", true).
+		Done().
+		Page(3, 20).
+		Recent().
+		Explain()
+
+	expected := []string{
+		`status = "active"  ->  status=active`,
+		`age >= "18"  ->  age_gte=18`,
+		`OR group "tier"`,
+		`isPremium = "true"  ->  %5Bor%3Atier%5DisPremium=true`,
+		"limit 20, offset 40  ->  limit=20&offset=40",
+		"created_at descending  ->  sort=created_at&order=desc",
+	}
+	for _, e := range expected {
+		if !strings.Contains(explanation, e) {
+			t.Errorf("Expected explanation to contain %q, got:\n%s", e, explanation)
+		}
+	}
+	if strings.Contains(explanation, "Warnings") {
+		t.Errorf("Expected no warnings, got:\n%s", explanation)
+	}
+}
+
+func TestExplainWarnings(t *testing.T) {
+	explanation := cocobase.NewQuery().
+		Matches("name", "[unclosed").
+		In("role").
+		Desc().
+		Explain()
+
+	expected := []string{
+		"Warnings:",
+		"name: invalid regular expression",
+		"role: in list is empty",
+		`order "desc" is set but no sort field was given`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(explanation, e) {
+			t.Errorf("Expected explanation to contain %q, got:\n%s", e, explanation)
+		}
+	}
+}
+
+func TestExplainQueryServerPlan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("explain") != "true" {
+			t.Errorf("Expected explain=true, got %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"index": "status_idx", "filters": 1})
+	}))
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	plan, err := client.ExplainQuery(context.Background(), "users", cocobase.NewQuery().Where("status", "active"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !plan.ServerSupported || plan.Server["index"] != "status_idx" {
+		t.Errorf("Expected server plan, got %+v", plan)
+	}
+	if plan.Query != "status=active" || plan.Description == "" {
+		t.Errorf("Expected local description, got %+v", plan)
+	}
+}
+
+func TestExplainQueryUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	plan, err := client.ExplainQuery(context.Background(), "users", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if plan.ServerSupported || plan.Server != nil {
+		t.Errorf("Expected no server plan, got %+v", plan)
+	}
+}
+
+// base64Codec is a toy codec whose wire format never starts with '{' or '['
+type base64Codec struct{}
+
+func (base64Codec) ContentType() string { return "application/x-base64-json" }
+
+func (base64Codec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return []byte(base64.StdEncoding.EncodeToString(data)), err
+}
+
+func (base64Codec) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, r))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func TestExplainQueryCustomCodec(t *testing.T) {
+	response := `{"index":"status_idx"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(base64.StdEncoding.EncodeToString([]byte(response))))
+	}))
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Codec: base64Codec{}})
+	plan, err := client.ExplainQuery(context.Background(), "users", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !plan.ServerSupported || plan.Server["index"] != "status_idx" {
+		t.Errorf("Expected server plan decoded by the codec, got %+v", plan)
+	}
+
+	response = `[{"id":"doc1"}]`
+	plan, err = client.ExplainQuery(context.Background(), "users", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if plan.ServerSupported || plan.Server != nil {
+		t.Errorf("Expected no server plan for a document list, got %+v", plan)
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func geoDocs() []cocobase.Document {
+	return []cocobase.Document{
+		{ID: "lagos", Data: map[string]interface{}{"location": cocobase.NewGeoPoint(6.5244, 3.3792).ToData()}},
+		{ID: "ikeja", Data: map[string]interface{}{"location": map[string]interface{}{"latitude": 6.6018, "longitude": 3.3515}}},
+		{ID: "abuja", Data: map[string]interface{}{"location": map[string]interface{}{"type": "Point", "coordinates": []interface{}{7.4951, 9.0579}}}},
+		{ID: "nowhere", Data: map[string]interface{}{"name": "no location"}},
+	}
+}
+
+func TestGeoPointDistance(t *testing.T) {
+	lagos := cocobase.NewGeoPoint(6.5244, 3.3792)
+	abuja := cocobase.NewGeoPoint(9.0579, 7.4951)
+
+	distance := lagos.DistanceTo(abuja)
+	if math.Abs(distance-536000) > 5000 {
+		t.Errorf("Expected about 536km between Lagos and Abuja, got %.0fm", distance)
+	}
+}
+
+func TestGeoPointSerialization(t *testing.T) {
+	data, err := json.Marshal(map[string]interface{}{"location": cocobase.NewGeoPoint(1.5, -2.25)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"location":{"lat":1.5,"lng":-2.25}}` {
+		t.Errorf("Unexpected serialization: %s", data)
+	}
+
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	point, ok := cocobase.ParseGeoPoint(decoded["location"])
+	if !ok || point.Lat != 1.5 || point.Lng != -2.25 {
+		t.Errorf("Round trip failed: %+v", point)
+	}
+}
+
+func TestGeoQueryParams(t *testing.T) {
+	query := cocobase.NewQuery().
+		Near("location", 6.5, 3.4, 1000).
+		WithinBox("location", cocobase.NewGeoPoint(6, 3), cocobase.NewGeoPoint(7, 4)).
+		OrderByDistance("location", 6.5, 3.4)
+
+	params := parseQuery(query.Build())
+
+	if params.Get("location_near") != "6.5,3.4,1000" {
+		t.Errorf("Expected location_near=6.5,3.4,1000, got %s", params.Get("location_near"))
+	}
+	if params.Get("location_withinbox") != "6,3,7,4" {
+		t.Errorf("Expected location_withinbox=6,3,7,4, got %s", params.Get("location_withinbox"))
+	}
+	if params.Get("sort") != "location" || params.Get("sort_origin") != "6.5,3.4" {
+		t.Errorf("Expected distance sort, got %v", params)
+	}
+}
+
+func TestGeoEvaluate(t *testing.T) {
+	docs := geoDocs()
+
+	assertIDs(t, cocobase.NewQuery().Near("location", 6.5244, 3.3792, 20000).Apply(docs), "lagos", "ikeja")
+	assertIDs(t, cocobase.NewQuery().
+		WithinBox("location", cocobase.NewGeoPoint(8, 7), cocobase.NewGeoPoint(10, 8)).
+		Apply(docs), "abuja")
+	assertIDs(t, cocobase.NewQuery().
+		WithinPolygon("location",
+			cocobase.NewGeoPoint(6.4, 3.3),
+			cocobase.NewGeoPoint(6.4, 3.5),
+			cocobase.NewGeoPoint(6.55, 3.5),
+			cocobase.NewGeoPoint(6.55, 3.3)).
+		Apply(docs), "lagos")
+	assertIDs(t, cocobase.NewQuery().OrderByDistance("location", 9, 7.5).Apply(docs), "abuja", "ikeja", "lagos", "nowhere")
+}
+
+func TestGeoClientSideFallback(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if strings.Contains(r.URL.RawQuery, "_near") {
+			http.Error(w, "unsupported operator", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(geoDocs())
+	}))
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	query := cocobase.NewQuery().
+		Near("location", 6.5244, 3.3792, 20000).
+		OrderByDistance("location", 6.6018, 3.3515).
+		Limit(1)
+
+	docs, err := client.ListDocuments(context.Background(), "places", query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	assertIDs(t, docs, "ikeja")
+	if len(queries) != 2 || queries[1] != "limit=100" {
+		t.Errorf("Expected a rejected geo request followed by an unfiltered one, got %v", queries)
+	}
+}
+
+func TestGeoClientSideFallbackPages(t *testing.T) {
+	var all []cocobase.Document
+	for i := 0; i < 250; i++ {
+		all = append(all, cocobase.Document{ID: strconv.Itoa(i), Data: map[string]interface{}{
+			"location": cocobase.NewGeoPoint(float64(i%90), 0).ToData(),
+		}})
+	}
+	var pages int
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		end := offset + limit
+		if end > len(all) {
+			end = len(all)
+		}
+		json.NewEncoder(w).Encode(all[offset:end])
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, ClientSideGeo: true})
+	query := cocobase.NewQuery().WithinBox("location", cocobase.NewGeoPoint(9.5, -1), cocobase.NewGeoPoint(10.5, 1))
+
+	docs, err := client.ListDocuments(context.Background(), "places", query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	assertIDs(t, docs, "10", "100", "190")
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+}
+
+func TestGeoClientSideRejectsInvalidRegex(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request for an invalid query")
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, ClientSideGeo: true})
+	query := cocobase.NewQuery().Matches("name", "([").Near("location", 6.5244, 3.3792, 1000)
+
+	if _, err := client.ListDocuments(context.Background(), "places", query); err == nil {
+		t.Error("Expected the invalid pattern to be reported")
+	}
+}
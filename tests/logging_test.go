@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"github.com/lordace-coder/cocobase-go/storage"
+)
+
+func authServer(t *testing.T) string {
+	t.Helper()
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth-collections/login", "/auth-collections/signup":
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "secret-token"})
+		case "/auth-collections/user":
+			json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1", Email: "a@b.c"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return server.URL
+}
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggingHTTPAndAuth(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client := cocobase.NewClient(cocobase.Config{
+		APIKey:  "super-secret-key",
+		BaseURL: authServer(t),
+		Storage: storage.NewMemoryStorage(),
+		Logger:  logger,
+	})
+
+	if err := client.Login(context.Background(), "a@b.c", "hunter2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.Logout()
+
+	output := buf.String()
+	for _, secret := range []string{"secret-token", "hunter2", "super-secret-key"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Log output leaked %q:\n%s", secret, output)
+		}
+	}
+
+	var sawRequest, sawSignIn, sawSignOut bool
+	for _, record := range decodeLogs(t, &buf) {
+		switch record["msg"] {
+		case "http request":
+			if record["operation"] == cocobase.OpAuthLogin {
+				sawRequest = record["method"] == "POST" &&
+					record["path"] == "/auth-collections/login" &&
+					record["status"] == float64(200) &&
+					record["attempt"] == float64(1) &&
+					record["duration"] != nil
+			}
+		case "signed in":
+			sawSignIn = record["user_id"] == "user1"
+		case "signed out":
+			sawSignOut = true
+		}
+	}
+	if !sawRequest || !sawSignIn || !sawSignOut {
+		t.Errorf("Missing expected records (request=%v signin=%v signout=%v):\n%s", sawRequest, sawSignIn, sawSignOut, output)
+	}
+}
+
+func TestLoggingRedactsUserAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var seen string
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: authServer(t),
+		Logger:  logger,
+		Middleware: []cocobase.Middleware{func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+			return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+				seen = req.Header.Get("Authorization")
+				return next(req, op)
+			}
+		}},
+	})
+	client.SetToken("abc.def.ghi")
+	client.GetCurrentUser(context.Background())
+
+	if seen != "Bearer abc.def.ghi" {
+		t.Fatalf("Expected bearer header to be sent, got %q", seen)
+	}
+	if strings.Contains(buf.String(), "abc.def.ghi") {
+		t.Errorf("Log output leaked token:\n%s", buf.String())
+	}
+}
+
+func TestLoggingDisabledByDefault(t *testing.T) {
+	client := cocobase.NewClient(cocobase.Config{BaseURL: authServer(t)})
+	if err := client.Login(context.Background(), "a@b.c", "pw"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestGenerateTOTP(t *testing.T) {
+	// RFC 6238 SHA-1 test vectors, truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := cocobase.GenerateTOTP(secret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("At %d: expected %s, got %s", unix, want, got)
+		}
+	}
+
+	if _, err := cocobase.GenerateTOTP("not base32!", time.Now()); err == nil {
+		t.Error("Expected an error for an invalid secret")
+	}
+}
+
+// mfaServer fakes sign-in with an optional TOTP second factor
+type mfaServer struct {
+	mu       sync.Mutex
+	secret   string
+	enabled  bool
+	recovery []string
+	URL      string
+}
+
+func newMFAServer(t *testing.T) *mfaServer {
+	t.Helper()
+	s := &mfaServer{}
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		token := strings.TrimPrefix(r.Header.Get(cocobase.HeaderAuthorization), "Bearer ")
+
+		switch r.URL.Path {
+		case "/auth-collections/login":
+			if s.enabled {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"mfa_required": true,
+					"mfa_token":    "challenge-1",
+					"mfa_methods":  []string{"totp", "recovery_code"},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "session-token"})
+		case "/auth-collections/mfa/verify":
+			if body["mfa_token"] != "challenge-1" || !s.accept(body["code"]) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "session-token"})
+		case "/auth-collections/user":
+			if token != "session-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1", Email: "alice@example.com"})
+		case "/auth-collections/mfa/totp/enroll":
+			s.secret = "JBSWY3DPEHPK3PXP"
+			json.NewEncoder(w).Encode(map[string]string{"secret": s.secret})
+		case "/auth-collections/mfa/totp/confirm":
+			if !s.accept(body["code"]) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.enabled = true
+			s.recovery = []string{"recovery-1", "recovery-2"}
+			json.NewEncoder(w).Encode(map[string][]string{"recovery_codes": s.recovery})
+		case "/auth-collections/mfa/recovery-codes":
+			if !s.accept(body["code"]) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.recovery = []string{"recovery-3"}
+			json.NewEncoder(w).Encode(map[string][]string{"recovery_codes": s.recovery})
+		case "/auth-collections/mfa/disable":
+			if !s.accept(body["code"]) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.enabled = false
+		}
+	})
+	s.URL = server.URL
+	return s
+}
+
+// accept checks a TOTP code, allowing the previous step, or consumes a
+// recovery code
+func (s *mfaServer) accept(code string) bool {
+	for _, at := range []time.Time{time.Now(), time.Now().Add(-30 * time.Second)} {
+		if want, _ := cocobase.GenerateTOTP(s.secret, at); s.secret != "" && code == want {
+			return true
+		}
+	}
+	for i, rc := range s.recovery {
+		if code == rc {
+			s.recovery = append(s.recovery[:i], s.recovery[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestMFAEnrollmentAndLogin(t *testing.T) {
+	server := newMFAServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	enrollment, err := client.EnrollTOTP(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/Cocobase:alice@example.com?") ||
+		!strings.Contains(enrollment.URI, "secret="+enrollment.Secret) {
+		t.Errorf("Unexpected otpauth URI %q", enrollment.URI)
+	}
+
+	if _, err := client.ConfirmTOTP(ctx, "000000x"); !errors.Is(err, cocobase.ErrInvalidMFACode) {
+		t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+	}
+	code, _ := cocobase.GenerateTOTP(enrollment.Secret, time.Now())
+	recovery, err := client.ConfirmTOTP(ctx, code)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recovery) != 2 {
+		t.Fatalf("Expected recovery codes, got %v", recovery)
+	}
+
+	client.Logout()
+
+	err = client.Login(ctx, "alice@example.com", "password")
+	var challenge *cocobase.MFAChallenge
+	if !errors.Is(err, cocobase.ErrMFARequired) || !errors.As(err, &challenge) {
+		t.Fatalf("Expected an MFA challenge, got %v", err)
+	}
+	if len(challenge.Methods) != 2 || client.IsAuthenticated() {
+		t.Errorf("Expected a pending challenge only, got %+v", challenge)
+	}
+
+	if err := client.VerifyMFA(ctx, "000000x"); !errors.Is(err, cocobase.ErrInvalidMFACode) {
+		t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+	}
+	code, _ = cocobase.GenerateTOTP(enrollment.Secret, time.Now())
+	if err := client.VerifyMFA(ctx, code); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user, err := client.GetCurrentUser(ctx); err != nil || user.ID != "user1" {
+		t.Errorf("Expected to be signed in, got %+v, %v", user, err)
+	}
+	if err := client.VerifyMFA(ctx, code); !errors.Is(err, cocobase.ErrNoMFAChallenge) {
+		t.Errorf("Expected the challenge to be used up, got %v", err)
+	}
+}
+
+func TestMFARecoveryCodes(t *testing.T) {
+	server := newMFAServer(t)
+	server.enabled = true
+	server.recovery = []string{"recovery-1"}
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	session, err := client.LoginSession(ctx, "alice@example.com", "password")
+	var challenge *cocobase.MFAChallenge
+	if session != nil || !errors.As(err, &challenge) {
+		t.Fatalf("Expected an MFA challenge, got %v", err)
+	}
+
+	session, err = client.VerifyMFASession(ctx, challenge, "recovery-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.User.ID != "user1" || client.IsAuthenticated() {
+		t.Errorf("Expected a session separate from the client, got %+v", session)
+	}
+
+	if _, err := client.VerifyMFASession(ctx, challenge, "recovery-1"); !errors.Is(err, cocobase.ErrInvalidMFACode) {
+		t.Errorf("Expected a used recovery code to be rejected, got %v", err)
+	}
+
+	if err := client.DisableMFA(session.Context(ctx), "recovery-2"); !errors.Is(err, cocobase.ErrInvalidMFACode) {
+		t.Errorf("Expected ErrInvalidMFACode, got %v", err)
+	}
+}
+
+func TestSessionMFACalls(t *testing.T) {
+	server := newMFAServer(t)
+	tokens := make(chan string, 10)
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Middleware: []cocobase.Middleware{func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+			return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+				if strings.Contains(req.URL.Path, "/mfa/") {
+					tokens <- strings.TrimPrefix(req.Header.Get(cocobase.HeaderAuthorization), "Bearer ")
+				}
+				return next(req, op)
+			}
+		}},
+	})
+	client.SetToken("client-token")
+	ctx := context.Background()
+
+	session, err := client.LoginSession(ctx, "alice@example.com", "password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	enrollment, err := session.EnrollTOTP(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/Cocobase:alice@example.com?") {
+		t.Errorf("Expected the session user in the otpauth URI, got %q", enrollment.URI)
+	}
+	code, _ := cocobase.GenerateTOTP(enrollment.Secret, time.Now())
+	if _, err := session.ConfirmTOTP(ctx, code); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recovery, err := session.RegenerateRecoveryCodes(ctx, code)
+	if err != nil || len(recovery) != 1 {
+		t.Fatalf("Expected new recovery codes, got %v, %v", recovery, err)
+	}
+	if err := session.DisableMFA(ctx, recovery[0]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	close(tokens)
+	var n int
+	for token := range tokens {
+		n++
+		if token != "session-token" {
+			t.Errorf("Expected the session's token, got %q", token)
+		}
+	}
+	if n != 4 {
+		t.Errorf("Expected 4 MFA requests, got %d", n)
+	}
+}
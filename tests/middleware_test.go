@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func documentServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	t.Helper()
+	if handler == nil {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Path == "/collections/users/documents" {
+				json.NewEncoder(w).Encode([]cocobase.Document{})
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1", Collection: "users"})
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMiddlewareReceivesOperation(t *testing.T) {
+	server := documentServer(t, nil)
+
+	var ops []cocobase.Operation
+	record := func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+		return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+			ops = append(ops, op)
+			return next(req, op)
+		}
+	}
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:    server.URL,
+		Middleware: []cocobase.Middleware{record},
+	})
+	ctx := context.Background()
+
+	client.GetDocument(ctx, "users", "doc1")
+	client.CreateDocument(ctx, "users", map[string]interface{}{"name": "x"})
+	client.ListDocuments(ctx, "users", nil)
+	client.DeleteDocument(ctx, "users", "doc1")
+
+	expected := []cocobase.Operation{
+		{Name: cocobase.OpDocumentsGet, Collection: "users", DocumentID: "doc1", Attempt: 1},
+		{Name: cocobase.OpDocumentsCreate, Collection: "users", Attempt: 1},
+		{Name: cocobase.OpDocumentsList, Collection: "users", Attempt: 1},
+		{Name: cocobase.OpDocumentsDelete, Collection: "users", DocumentID: "doc1", Attempt: 1},
+	}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %d operations, got %+v", len(expected), ops)
+	}
+	for i := range expected {
+		if ops[i] != expected[i] {
+			t.Errorf("Operation %d: expected %+v, got %+v", i, expected[i], ops[i])
+		}
+	}
+}
+
+func TestMiddlewareOrderAndHeaders(t *testing.T) {
+	var header string
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Trace")
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	var order []string
+	named := func(name string) cocobase.Middleware {
+		return func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+			return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+				order = append(order, name)
+				req.Header.Set("X-Trace", req.Header.Get("X-Trace")+name)
+				return next(req, op)
+			}
+		}
+	}
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:    server.URL,
+		Middleware: []cocobase.Middleware{named("a"), named("b")},
+	})
+
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != "a" || order[1] != "b" || header != "ab" {
+		t.Errorf("Expected outer-to-inner order a,b and header ab, got %v and %q", order, header)
+	}
+}
+
+func TestMiddlewareSeesErrorResponses(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "missing", http.StatusNotFound)
+	})
+
+	var status int
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Middleware: []cocobase.Middleware{func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+			return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+				resp, err := next(req, op)
+				if resp != nil {
+					status = resp.StatusCode
+				}
+				return resp, err
+			}
+		}},
+	})
+
+	_, err := client.GetDocument(context.Background(), "users", "missing")
+	var apiErr *cocobase.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected APIError 404, got %v", err)
+	}
+	if status != http.StatusNotFound {
+		t.Errorf("Expected middleware to see raw 404, got %d", status)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Request should not reach the server")
+	})
+
+	blocked := errors.New("blocked by policy")
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Middleware: []cocobase.Middleware{func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+			return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+				if op.Name == cocobase.OpAuthLogin {
+					return nil, blocked
+				}
+				return next(req, op)
+			}
+		}},
+	})
+
+	if err := client.Login(context.Background(), "a@b.c", "secret"); !errors.Is(err, blocked) {
+		t.Errorf("Expected middleware error, got %v", err)
+	}
+}
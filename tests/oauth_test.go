@@ -0,0 +1,287 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+// oauthServer plays both the OAuth provider (/authorize) and Cocobase's code
+// exchange, checking the PKCE verifier against the challenge
+type oauthServer struct {
+	mu         sync.Mutex
+	challenges map[string]string // code -> code_challenge
+	deny       bool
+	mfa        bool
+	URL        string
+}
+
+func newOAuthServer(t *testing.T) *oauthServer {
+	t.Helper()
+	s := &oauthServer{challenges: make(map[string]string)}
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/authorize":
+			q := r.URL.Query()
+			redirect, _ := url.Parse(q.Get("redirect_uri"))
+			params := url.Values{"state": {q.Get("state")}}
+			if s.deny {
+				params.Set("error", "access_denied")
+			} else if q.Get("code_challenge_method") == "S256" && q.Get("client_id") == "client-id" {
+				code := "code-" + q.Get("state")
+				s.challenges[code] = q.Get("code_challenge")
+				params.Set("code", code)
+			}
+			redirect.RawQuery = params.Encode()
+			http.Redirect(w, r, redirect.String(), http.StatusFound)
+		case "/auth-collections/oauth/github":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			sum := sha256.Sum256([]byte(body["code_verifier"]))
+			if challenge, ok := s.challenges[body["code"]]; !ok || challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			delete(s.challenges, body["code"])
+			if s.mfa {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"mfa_required": true,
+					"mfa_token":    "challenge-1",
+					"mfa_methods":  []string{"totp"},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "github-token", RefreshToken: "github-refresh"})
+		case "/auth-collections/mfa/verify":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["mfa_token"] != "challenge-1" || body["code"] != "123456" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "github-token"})
+		case "/auth-collections/user":
+			if r.Header.Get(cocobase.HeaderAuthorization) != "Bearer github-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1", Email: "alice@example.com"})
+		}
+	})
+	s.URL = server.URL
+	return s
+}
+
+func (s *oauthServer) config() cocobase.OAuthConfig {
+	return cocobase.OAuthConfig{
+		Provider: "github",
+		AuthURL:  s.URL + "/authorize",
+		ClientID: "client-id",
+		Scopes:   []string{"read:user", "user:email"},
+		// Stands in for the user's browser
+		OpenURL: func(authURL string) error {
+			go func() {
+				resp, err := http.Get(authURL)
+				if err == nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+			}()
+			return nil
+		},
+	}
+}
+
+func TestNewOAuthFlow(t *testing.T) {
+	client := cocobase.NewClient(cocobase.Config{})
+	flow, err := client.NewOAuthFlow(cocobase.OAuthConfig{
+		Provider: "google",
+		AuthURL:  cocobase.GoogleAuthURL,
+		ClientID: "client-id",
+		Scopes:   []string{"openid", "email"},
+		Params:   map[string]string{"prompt": "consent"},
+	}, "http://127.0.0.1:8085/callback")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	u, _ := url.Parse(flow.AuthURL)
+	q := u.Query()
+	sum := sha256.Sum256([]byte(flow.CodeVerifier))
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"redirect_uri":          "http://127.0.0.1:8085/callback",
+		"scope":                 "openid email",
+		"state":                 flow.State,
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+		"prompt":                "consent",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("Expected %s=%q, got %q", k, v, q.Get(k))
+		}
+	}
+	if !strings.HasPrefix(flow.AuthURL, cocobase.GoogleAuthURL+"?") {
+		t.Errorf("Expected the provider's endpoint, got %s", flow.AuthURL)
+	}
+	if len(flow.CodeVerifier) < 43 {
+		t.Errorf("Expected a PKCE verifier of at least 43 characters, got %d", len(flow.CodeVerifier))
+	}
+
+	if _, err := client.NewOAuthFlow(cocobase.OAuthConfig{Provider: "google"}, ""); !errors.Is(err, cocobase.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestLoginWithOAuth(t *testing.T) {
+	server := newOAuthServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []cocobase.AuthEventType
+	client.OnAuthStateChange(func(e cocobase.AuthEvent) {
+		events = append(events, e.Type)
+	})
+
+	if err := client.LoginWithOAuth(ctx, server.config()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if client.GetToken() != "github-token" {
+		t.Errorf("Expected the exchanged token, got %q", client.GetToken())
+	}
+	user, err := client.GetCurrentUser(ctx)
+	if err != nil || user.ID != "user1" {
+		t.Errorf("Expected the signed-in user, got %+v, %v", user, err)
+	}
+	if len(events) != 1 || events[0] != cocobase.AuthSignedIn {
+		t.Errorf("Expected a signed-in event, got %v", events)
+	}
+}
+
+func TestLoginWithOAuthDenied(t *testing.T) {
+	server := newOAuthServer(t)
+	server.deny = true
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pages := make(chan string, 1)
+	config := server.config()
+	config.OpenURL = func(authURL string) error {
+		go func() {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				pages <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			page, _ := io.ReadAll(resp.Body)
+			pages <- string(page)
+		}()
+		return nil
+	}
+
+	err := client.LoginWithOAuth(ctx, config)
+	var oauthErr *cocobase.OAuthError
+	if !errors.As(err, &oauthErr) || oauthErr.Code != "access_denied" {
+		t.Fatalf("Expected an access_denied OAuthError, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client to stay signed out")
+	}
+	if page := <-pages; !strings.Contains(page, "access_denied") || strings.Contains(page, "complete") {
+		t.Errorf("Expected the browser to be told sign-in failed, got %q", page)
+	}
+}
+
+func TestLoginWithOAuthTimesOut(t *testing.T) {
+	server := newOAuthServer(t)
+	config := server.config()
+	config.OpenURL = func(string) error { return nil } // the user never signs in
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.LoginWithOAuth(ctx, config); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context deadline, got %v", err)
+	}
+}
+
+func TestLoginWithOAuthRequiresMFA(t *testing.T) {
+	server := newOAuthServer(t)
+	server.mfa = true
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := client.LoginWithOAuth(ctx, server.config())
+	var challenge *cocobase.MFAChallenge
+	if !errors.Is(err, cocobase.ErrMFARequired) || !errors.As(err, &challenge) {
+		t.Fatalf("Expected an MFA challenge, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected no session before the second factor")
+	}
+
+	if err := client.VerifyMFA(ctx, "123456"); err != nil {
+		t.Fatalf("Expected the challenge to be held by the client, got %v", err)
+	}
+	if user, err := client.GetCurrentUser(ctx); err != nil || user.ID != "user1" {
+		t.Errorf("Expected to be signed in, got %+v, %v", user, err)
+	}
+}
+
+func TestCompleteOAuthChecksState(t *testing.T) {
+	client := cocobase.NewClient(cocobase.Config{})
+	flow := &cocobase.OAuthFlow{Provider: "github", State: "expected"}
+
+	err := client.CompleteOAuth(context.Background(), flow, url.Values{"state": {"forged"}, "code": {"code"}})
+	if !errors.Is(err, cocobase.ErrOAuthStateMismatch) {
+		t.Errorf("Expected ErrOAuthStateMismatch, got %v", err)
+	}
+}
+
+func TestExchangeOAuthCodeSession(t *testing.T) {
+	server := newOAuthServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	client.SetToken("client-token")
+
+	flow, err := client.NewOAuthFlow(server.config(), "http://127.0.0.1:8085/callback")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sum := sha256.Sum256([]byte(flow.CodeVerifier))
+	server.mu.Lock()
+	server.challenges["code-1"] = base64.RawURLEncoding.EncodeToString(sum[:])
+	server.mu.Unlock()
+
+	session, err := client.ExchangeOAuthCodeSession(context.Background(), flow, "code-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.Token != "github-token" || session.RefreshToken != "github-refresh" || session.User.ID != "user1" {
+		t.Errorf("Unexpected session %+v", session)
+	}
+	if got := client.GetToken(); got != "client-token" {
+		t.Errorf("Expected the client session to be unchanged, got %q", got)
+	}
+}
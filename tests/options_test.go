@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client, err := cocobase.New(
+		cocobase.WithAPIKey("key-123"),
+		cocobase.WithBaseURL(server.URL+"/"),
+		cocobase.WithTimeout(5*time.Second),
+		cocobase.WithUserAgent("my-service/1.0"),
+		cocobase.WithHeader("X-Tenant", "acme"),
+		cocobase.WithHeader(cocobase.HeaderAPIKey, "ignored"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	h := <-headers
+	if got := h.Get("User-Agent"); got != "my-service/1.0" {
+		t.Errorf("Expected custom user agent, got %q", got)
+	}
+	if got := h.Get("X-Tenant"); got != "acme" {
+		t.Errorf("Expected X-Tenant header, got %q", got)
+	}
+	if got := h.Get(cocobase.HeaderAPIKey); got != "key-123" {
+		t.Errorf("Expected headers not to override the API key, got %q", got)
+	}
+}
+
+func TestHeadersCannotSetCredentials(t *testing.T) {
+	headers := make(chan http.Header, 2)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Headers: http.Header{
+			"x-api-key":                  {"configured-key"},
+			cocobase.HeaderAuthorization: {"Bearer configured-token"},
+			"X-Tenant":                   {"acme"},
+		},
+	})
+	ctx := context.Background()
+
+	client.GetDocument(ctx, "users", "doc1")
+	h := <-headers
+	if h.Get(cocobase.HeaderAPIKey) != "" || h.Get(cocobase.HeaderAuthorization) != "" {
+		t.Errorf("Expected configured credentials to be dropped, got %v", h)
+	}
+	if h.Get("X-Tenant") != "acme" {
+		t.Errorf("Expected X-Tenant header, got %q", h.Get("X-Tenant"))
+	}
+
+	client.SetToken("user-token")
+	client.GetDocument(ctx, "users", "doc1")
+	if got := (<-headers).Values(cocobase.HeaderAuthorization); len(got) != 1 || got[0] != "Bearer user-token" {
+		t.Errorf("Expected only the user's token, got %v", got)
+	}
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	client.GetDocument(context.Background(), "users", "doc1")
+
+	if got := (<-headers).Get("User-Agent"); got != cocobase.DefaultUserAgent {
+		t.Errorf("Expected %q, got %q", cocobase.DefaultUserAgent, got)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []cocobase.Option
+	}{
+		{"missing scheme", []cocobase.Option{cocobase.WithBaseURL("api.cocobase.com")}},
+		{"unsupported scheme", []cocobase.Option{cocobase.WithBaseURL("ftp://api.cocobase.com")}},
+		{"query in base URL", []cocobase.Option{cocobase.WithBaseURL("https://api.cocobase.com?x=1")}},
+		{"unparseable base URL", []cocobase.Option{cocobase.WithBaseURL("https://[::1")}},
+		{"negative timeout", []cocobase.Option{cocobase.WithTimeout(-time.Second)}},
+		{"malformed proxy", []cocobase.Option{cocobase.WithProxy("not a proxy")}},
+		{"timeout with custom client", []cocobase.Option{
+			cocobase.WithHTTPClient(&http.Client{}),
+			cocobase.WithTimeout(time.Second),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cocobase.New(tt.opts...); !errors.Is(err, cocobase.ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	apiKeys := make(chan string, 1)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		apiKeys <- r.Header.Get(cocobase.HeaderAPIKey)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	t.Setenv(cocobase.EnvAPIKey, "env-key")
+	t.Setenv(cocobase.EnvBaseURL, server.URL)
+	t.Setenv(cocobase.EnvTimeout, "10s")
+
+	client, err := cocobase.NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := <-apiKeys; got != "env-key" {
+		t.Errorf("Expected API key from environment, got %q", got)
+	}
+
+	client, err = cocobase.NewClientFromEnv(cocobase.WithAPIKey("override"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.GetDocument(context.Background(), "users", "doc1")
+	if got := <-apiKeys; got != "override" {
+		t.Errorf("Expected option to override the environment, got %q", got)
+	}
+}
+
+func TestNewClientFromEnvInvalidTimeout(t *testing.T) {
+	t.Setenv(cocobase.EnvTimeout, "soon")
+	if _, err := cocobase.NewClientFromEnv(); !errors.Is(err, cocobase.ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := cocobase.New(
+		cocobase.WithBaseURL("http://cocobase.invalid"),
+		cocobase.WithProxy(proxy.URL),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := <-proxied; got != "http://cocobase.invalid/collections/users/documents/doc1" {
+		t.Errorf("Expected request through the proxy, got %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"github.com/lordace-coder/cocobase-go/storage"
+)
+
+// passwordlessServer sends at most one link or code per email and accepts
+// the code "123456" and the link token "link-token"
+type passwordlessServer struct {
+	mu   sync.Mutex
+	sent map[string]map[string]string
+	URL  string
+}
+
+func newPasswordlessServer(t *testing.T) *passwordlessServer {
+	t.Helper()
+	s := &passwordlessServer{sent: make(map[string]map[string]string)}
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/auth-collections/magic-link", "/auth-collections/login-code":
+			if _, ok := s.sent[body["email"]]; ok {
+				w.Header().Set(cocobase.HeaderRetryAfter, "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			s.sent[body["email"]] = body
+		case "/auth-collections/magic-link/verify":
+			if body["token"] != "link-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"detail":"Link expired"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "session-token"})
+		case "/auth-collections/login-code/verify":
+			if body["code"] != "123456" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.TokenResponse{AccessToken: "session-token"})
+		case "/auth-collections/user":
+			json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1", Email: "alice@example.com"})
+		}
+	})
+	s.URL = server.URL
+	return s
+}
+
+func TestLoginCode(t *testing.T) {
+	server := newPasswordlessServer(t)
+	store := storage.NewMemoryStorage()
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	ctx := context.Background()
+
+	if err := client.SendLoginCode(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err := client.SendLoginCode(ctx, "alice@example.com")
+	var rlErr *cocobase.RateLimitError
+	if !errors.As(err, &rlErr) || !errors.Is(err, cocobase.ErrRateLimited) {
+		t.Fatalf("Expected a RateLimitError, got %v", err)
+	}
+	if rlErr.RetryAfter != time.Minute {
+		t.Errorf("Expected RetryAfter from the header, got %v", rlErr.RetryAfter)
+	}
+	var apiErr *cocobase.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected the APIError to be wrapped, got %v", err)
+	}
+
+	if err := client.VerifyLoginCode(ctx, "alice@example.com", "000000"); !errors.Is(err, cocobase.ErrTokenInvalid) {
+		t.Errorf("Expected ErrTokenInvalid, got %v", err)
+	}
+	if err := client.VerifyLoginCode(ctx, "alice@example.com", "123456"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if client.GetToken() != "session-token" {
+		t.Errorf("Expected to be signed in, got token %q", client.GetToken())
+	}
+	if token, _ := store.Get("cocobase-token"); token != "session-token" {
+		t.Errorf("Expected the token in storage, got %q", token)
+	}
+	if _, err := store.Get("cocobase-user"); err != nil {
+		t.Errorf("Expected the user in storage, got %v", err)
+	}
+}
+
+func TestMagicLink(t *testing.T) {
+	server := newPasswordlessServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.SendMagicLink(ctx, "alice@example.com", "https://app.example.com/signed-in"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := server.sent["alice@example.com"]["redirect_url"]; got != "https://app.example.com/signed-in" {
+		t.Errorf("Expected the redirect URL to be sent, got %q", got)
+	}
+
+	if err := client.VerifyMagicLink(ctx, "old-token"); !errors.Is(err, cocobase.ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+	if err := client.VerifyMagicLink(ctx, "link-token"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user, err := client.GetCurrentUser(ctx); err != nil || user.ID != "user1" {
+		t.Errorf("Expected to be signed in, got %+v, %v", user, err)
+	}
+}
+
+func TestPasswordlessSessions(t *testing.T) {
+	server := newPasswordlessServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	client.SetToken("client-token")
+	ctx := context.Background()
+
+	if _, err := client.VerifyLoginCodeSession(ctx, "alice@example.com", "000000"); !errors.Is(err, cocobase.ErrTokenInvalid) {
+		t.Errorf("Expected ErrTokenInvalid, got %v", err)
+	}
+	session, err := client.VerifyLoginCodeSession(ctx, "alice@example.com", "123456")
+	if err != nil || session.Token != "session-token" || session.User.ID != "user1" {
+		t.Fatalf("Expected a session, got %+v, %v", session, err)
+	}
+
+	if _, err := client.VerifyMagicLinkSession(ctx, "old-token"); !errors.Is(err, cocobase.ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+	session, err = client.VerifyMagicLinkSession(ctx, "link-token")
+	if err != nil || session.Token != "session-token" {
+		t.Fatalf("Expected a session, got %+v, %v", session, err)
+	}
+
+	if got := client.GetToken(); got != "client-token" {
+		t.Errorf("Expected the client session to be unchanged, got %q", got)
+	}
+}
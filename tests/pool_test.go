@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"github.com/lordace-coder/cocobase-go/storage"
+)
+
+func TestClientPoolCachesPerProject(t *testing.T) {
+	apiKeys := make(chan string, 3)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		apiKeys <- r.Header.Get(cocobase.HeaderAPIKey)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	pool := cocobase.NewClientPool(cocobase.PoolConfig{
+		Base: cocobase.Config{BaseURL: server.URL},
+	})
+
+	a := pool.Get("project-a")
+	if pool.Get("project-a") != a {
+		t.Error("Expected the same client for the same project")
+	}
+	b := pool.Get("project-b")
+	if b == a {
+		t.Error("Expected different clients for different projects")
+	}
+	if pool.Len() != 2 {
+		t.Errorf("Expected 2 clients, got %d", pool.Len())
+	}
+
+	ctx := context.Background()
+	a.GetDocument(ctx, "users", "doc1")
+	b.GetDocument(ctx, "users", "doc1")
+	if got := <-apiKeys; got != "project-a" {
+		t.Errorf("Expected project-a API key, got %q", got)
+	}
+	if got := <-apiKeys; got != "project-b" {
+		t.Errorf("Expected project-b API key, got %q", got)
+	}
+}
+
+func TestClientPoolIsolatesState(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user-a"})
+	})
+
+	shared := storage.NewMemoryStorage()
+	pool := cocobase.NewClientPool(cocobase.PoolConfig{
+		Base: cocobase.Config{BaseURL: server.URL, Storage: shared},
+	})
+
+	a := pool.Get("project-a")
+	b := pool.Get("project-b")
+	a.SetToken("token-a")
+
+	if b.IsAuthenticated() {
+		t.Error("Expected project-b to be unaffected by project-a's token")
+	}
+	if _, err := shared.Get("cocobase-token"); err == nil {
+		t.Error("Expected tokens to be stored under a project namespace")
+	}
+
+	pool.Remove("project-a")
+	restored := pool.Get("project-a")
+	if err := restored.InitAuth(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restored.GetToken() != "token-a" {
+		t.Errorf("Expected token-a restored from namespaced storage, got %q", restored.GetToken())
+	}
+}
+
+func TestClientPoolEvictsIdleClients(t *testing.T) {
+	pool := cocobase.NewClientPool(cocobase.PoolConfig{IdleTimeout: 20 * time.Millisecond})
+
+	pool.Get("project-a")
+	time.Sleep(30 * time.Millisecond)
+	pool.EvictIdle()
+
+	if pool.Len() != 0 {
+		t.Errorf("Expected idle client to be evicted, %d left", pool.Len())
+	}
+}
+
+func TestClientPoolFromContext(t *testing.T) {
+	pool := cocobase.NewClientPool(cocobase.PoolConfig{})
+
+	if _, err := pool.FromContext(context.Background()); !errors.Is(err, cocobase.ErrNoTenant) {
+		t.Errorf("Expected ErrNoTenant, got %v", err)
+	}
+
+	ctx := cocobase.WithTenant(context.Background(), "project-a")
+	client, err := pool.FromContext(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client != pool.Get("project-a") {
+		t.Error("Expected the pooled client for the context's project")
+	}
+}
@@ -212,6 +212,98 @@ func TestIsNotNull(t *testing.T) {
 	}
 }
 
+func TestCaseSensitiveStringOperators(t *testing.T) {
+	query := cocobase.NewQuery().
+		ContainsCaseSensitive("name", "John").
+		StartsWithCaseSensitive("code", "AB").
+		EndsWithCaseSensitive("file", ".GO")
+
+	result := query.Build()
+	params := parseQuery(result)
+
+	if params.Get("name_containscs") != "John" {
+		t.Errorf("Expected name_containscs=John, got %s", result)
+	}
+	if params.Get("code_startswithcs") != "AB" {
+		t.Errorf("Expected code_startswithcs=AB, got %s", result)
+	}
+	if params.Get("file_endswithcs") != ".GO" {
+		t.Errorf("Expected file_endswithcs=.GO, got %s", result)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	query := cocobase.NewQuery().
+		Matches("sku", "^[A-Z]{3}-\\d+$")
+
+	result := query.Build()
+
+	if !hasParam(result, "sku_regex", "^[A-Z]{3}-\\d+$") {
+		t.Errorf("Expected sku_regex pattern, got %s", result)
+	}
+}
+
+func TestArrayOperators(t *testing.T) {
+	query := cocobase.NewQuery().
+		ArrayContains("tags", "go").
+		ArrayContainsAny("labels", "bug", "urgent").
+		ArrayContainsAll("roles", "admin", "editor").
+		SizeEquals("members", 3)
+
+	result := query.Build()
+	params := parseQuery(result)
+
+	if params.Get("tags_arraycontains") != "go" {
+		t.Errorf("Expected tags_arraycontains=go, got %s", result)
+	}
+	if params.Get("labels_arraycontainsany") != "bug,urgent" {
+		t.Errorf("Expected labels_arraycontainsany=bug,urgent, got %s", result)
+	}
+	if params.Get("roles_arraycontainsall") != "admin,editor" {
+		t.Errorf("Expected roles_arraycontainsall=admin,editor, got %s", result)
+	}
+	if params.Get("members_size") != "3" {
+		t.Errorf("Expected members_size=3, got %s", result)
+	}
+}
+
+func TestExists(t *testing.T) {
+	query := cocobase.NewQuery().
+		Exists("nickname").
+		NotExists("legacyId")
+
+	result := query.Build()
+	params := parseQuery(result)
+
+	if params.Get("nickname_exists") != "true" {
+		t.Errorf("Expected nickname_exists=true, got %s", result)
+	}
+	if params.Get("legacyId_exists") != "false" {
+		t.Errorf("Expected legacyId_exists=false, got %s", result)
+	}
+}
+
+func TestOrArrayOperators(t *testing.T) {
+	query := cocobase.NewQuery().
+		Or().
+		ArrayContains("tags", "go").
+		Matches("name", "^co").
+		Exists("featured").
+		Done()
+
+	result := query.Build()
+
+	if !strings.Contains(result, "%5Bor%5Dtags_arraycontains=go") {
+		t.Errorf("Expected [or]tags_arraycontains=go, got %s", result)
+	}
+	if !strings.Contains(result, "%5Bor%5Dname_regex=%5Eco") {
+		t.Errorf("Expected [or]name_regex=^co, got %s", result)
+	}
+	if !strings.Contains(result, "%5Bor%5Dfeatured_exists=true") {
+		t.Errorf("Expected [or]featured_exists=true, got %s", result)
+	}
+}
+
 // ============================================
 // 4. SIMPLE OR CONDITIONS
 // ============================================
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestRateLimitPacesRequests(t *testing.T) {
+	server := documentServer(t, nil)
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		RateLimit: &cocobase.RateLimitConfig{
+			Global: cocobase.RateLimit{RequestsPerSecond: 20, Burst: 1},
+		},
+	})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 5 requests at 20/s to take about 200ms, took %s", elapsed)
+	}
+}
+
+func TestRateLimitMaxInFlight(t *testing.T) {
+	var current, peak int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		RateLimit: &cocobase.RateLimitConfig{
+			Global: cocobase.RateLimit{MaxInFlight: 2},
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetDocument(context.Background(), "users", "doc1")
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, saw %d", peak)
+	}
+}
+
+func TestRateLimitRespectsContext(t *testing.T) {
+	server := documentServer(t, nil)
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		RateLimit: &cocobase.RateLimitConfig{
+			PerCollection: map[string]cocobase.RateLimit{
+				"slow": {RequestsPerSecond: 0.1, Burst: 1},
+			},
+		},
+	})
+
+	if _, err := client.GetDocument(context.Background(), "slow", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Other collections are not affected by the per-collection limit
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetDocument(ctx, "slow", "doc1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while waiting for the limiter, got %v", err)
+	}
+}
+
+func TestRateLimitCollectionWaitKeepsGlobalSlotFree(t *testing.T) {
+	server := documentServer(t, nil)
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		RateLimit: &cocobase.RateLimitConfig{
+			Global: cocobase.RateLimit{MaxInFlight: 1},
+			PerCollection: map[string]cocobase.RateLimit{
+				"slow": {RequestsPerSecond: 0.1, Burst: 1},
+			},
+		},
+	})
+
+	if _, err := client.GetDocument(context.Background(), "slow", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	waiting := make(chan struct{})
+	go func() {
+		defer close(waiting)
+		client.GetDocument(context.Background(), "slow", "doc1", cocobase.WithCallTimeout(300*time.Millisecond))
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected other collections not to wait behind a throttled one, took %s", elapsed)
+	}
+	<-waiting
+}
+
+func TestRateLimitAdaptsToTooManyRequests(t *testing.T) {
+	var calls int32
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0.2")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:   server.URL,
+		RateLimit: &cocobase.RateLimitConfig{},
+	})
+
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err == nil {
+		t.Fatalf("Expected 429 error")
+	}
+
+	start := time.Now()
+	if _, err := client.GetDocument(context.Background(), "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the client to wait for Retry-After, waited %s", elapsed)
+	}
+}
+
+func TestRateLimitIgnoresPerEmailThrottling(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth-collections/login-code" {
+			w.Header().Set(cocobase.HeaderRetryAfter, "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode([]cocobase.Document{{ID: "doc1"}})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:   server.URL,
+		RateLimit: &cocobase.RateLimitConfig{},
+	})
+	ctx := context.Background()
+
+	if err := client.SendLoginCode(ctx, "alice@example.com"); !errors.Is(err, cocobase.ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.ListDocuments(ctx, "notes", nil, cocobase.WithCallTimeout(time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected a per-email 429 not to pause other requests, waited %s", elapsed)
+	}
+}
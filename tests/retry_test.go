@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func TestCreateDocumentRetriesWithStableIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(cocobase.HeaderIdempotencyKey))
+		n := len(keys)
+		mu.Unlock()
+
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	var attempts []int
+	record := func(next cocobase.RoundTripFunc) cocobase.RoundTripFunc {
+		return func(req *http.Request, op cocobase.Operation) (*http.Response, error) {
+			attempts = append(attempts, op.Attempt)
+			return next(req, op)
+		}
+	}
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:    server.URL,
+		Retry:      &cocobase.RetryConfig{MaxAttempts: 3, Backoff: time.Millisecond},
+		Middleware: []cocobase.Middleware{record},
+	})
+
+	doc, err := client.CreateDocument(context.Background(), "users", map[string]interface{}{"name": "Alice"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if doc.ID != "doc1" {
+		t.Errorf("Expected doc1, got %s", doc.ID)
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Errorf("Expected the same idempotency key on every attempt, got %v", keys)
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("Expected attempts 1..3, got %v", attempts)
+	}
+}
+
+func TestCreateDocumentKeysDifferPerCall(t *testing.T) {
+	keys := make(chan string, 2)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get(cocobase.HeaderIdempotencyKey)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	client.CreateDocument(context.Background(), "users", map[string]interface{}{})
+	client.CreateDocument(context.Background(), "users", map[string]interface{}{})
+
+	if first, second := <-keys, <-keys; first == second {
+		t.Errorf("Expected a new key per create, got %q twice", first)
+	}
+}
+
+func TestReplayedCreateReturnsOriginal(t *testing.T) {
+	seen := make(map[string]bool)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(cocobase.HeaderIdempotencyKey)
+		if seen[key] {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"detail":"duplicate request"}`))
+			return
+		}
+		seen[key] = true
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "order-1", Data: map[string]interface{}{"total": 42.0}})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+	data := map[string]interface{}{"total": 42}
+
+	first, err := client.CreateDocument(ctx, "orders", data, cocobase.WithIdempotencyKey("order-abc"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	replay, err := client.CreateDocument(ctx, "orders", data, cocobase.WithIdempotencyKey("order-abc"))
+	if err != nil {
+		t.Fatalf("Expected replay to return the original, got %v", err)
+	}
+	if replay.ID != first.ID {
+		t.Errorf("Expected %s, got %s", first.ID, replay.ID)
+	}
+}
+
+func TestDuplicateCreateFromResponseBody(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(cocobase.HeaderIdempotentReplayed, "true")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "order-1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	doc, err := client.CreateDocument(context.Background(), "orders", map[string]interface{}{},
+		cocobase.WithIdempotencyKey("order-abc"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if doc.ID != "order-1" {
+		t.Errorf("Expected original document from the 409 body, got %s", doc.ID)
+	}
+}
+
+func TestConflictWithoutReplayIsAnError(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// A unique field violation naming the existing document
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "someone-else"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	doc, err := client.CreateDocument(context.Background(), "users", map[string]interface{}{"email": "alice@example.com"})
+	var apiErr *cocobase.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("Expected the 409 APIError, got %v, %v", doc, err)
+	}
+}
+
+func TestRetryDoesNotRepeatUnkeyedWrites(t *testing.T) {
+	var hits int
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Retry:   &cocobase.RetryConfig{MaxAttempts: 3, Backoff: time.Millisecond},
+	})
+
+	if _, err := client.UpdateDocument(context.Background(), "users", "doc1", map[string]interface{}{}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if hits != 1 {
+		t.Errorf("Expected PATCH without an idempotency key not to be retried, got %d attempts", hits)
+	}
+}
+
+func TestRetryBackoffDoesNotOverflow(t *testing.T) {
+	var hits int
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL: server.URL,
+		Retry: &cocobase.RetryConfig{
+			MaxAttempts: 6,
+			Backoff:     1 << 62,
+			MaxBackoff:  10 * time.Millisecond,
+		},
+	})
+
+	start := time.Now()
+	client.GetDocument(context.Background(), "users", "doc1")
+	if hits != 6 {
+		t.Fatalf("Expected 6 attempts, got %d", hits)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected every retry to wait MaxBackoff, took %s", elapsed)
+	}
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func articleIndex() *cocobase.SearchIndex {
+	idx := cocobase.NewSearchIndex("title", "body")
+	idx.Add(
+		cocobase.Document{ID: "a1", Data: map[string]interface{}{
+			"title": "Getting started with Cocobase",
+			"body":  "Cocobase is a backend as a service for Go developers.",
+		}},
+		cocobase.Document{ID: "a2", Data: map[string]interface{}{
+			"title": "Realtime updates",
+			"body":  "Watch collections over WebSocket with the Cocobase client.",
+		}},
+		cocobase.Document{ID: "a3", Data: map[string]interface{}{
+			"title": "Query operators",
+			"body":  "Filter documents with operators like contains and startswith.",
+		}},
+	)
+	return idx
+}
+
+func hitIDs(result cocobase.SearchResult) []string {
+	ids := make([]string, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.Document.ID
+	}
+	return ids
+}
+
+func TestSearchIndexRanking(t *testing.T) {
+	idx := articleIndex()
+
+	result := idx.Search(cocobase.SearchRequest{
+		Query:  "cocobase",
+		Boosts: map[string]float64{"title": 3},
+	})
+
+	ids := hitIDs(result)
+	if len(ids) != 2 || ids[0] != "a1" {
+		t.Fatalf("Expected title match a1 ranked first, got %v", ids)
+	}
+	if result.Hits[0].Score <= result.Hits[1].Score {
+		t.Errorf("Expected descending scores, got %v", result.Hits)
+	}
+	if !result.Local {
+		t.Errorf("Expected local result")
+	}
+}
+
+func TestSearchIndexModes(t *testing.T) {
+	idx := articleIndex()
+
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "cocobase websocket"})); len(ids) != 1 || ids[0] != "a2" {
+		t.Errorf("Expected all-terms match a2, got %v", ids)
+	}
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "websocket operators", Mode: cocobase.SearchMatchAny})); len(ids) != 2 {
+		t.Errorf("Expected any-term match on 2 docs, got %v", ids)
+	}
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "cocobase client", Mode: cocobase.SearchPhrase})); len(ids) != 1 || ids[0] != "a2" {
+		t.Errorf("Expected phrase match a2, got %v", ids)
+	}
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "client cocobase", Mode: cocobase.SearchPhrase})); len(ids) != 0 {
+		t.Errorf("Expected no phrase match for reversed terms, got %v", ids)
+	}
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "oper", Prefix: true})); len(ids) != 1 || ids[0] != "a3" {
+		t.Errorf("Expected prefix match a3, got %v", ids)
+	}
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "realtme", Typo: &cocobase.TypoTolerance{}})); len(ids) != 1 || ids[0] != "a2" {
+		t.Errorf("Expected typo-tolerant match a2, got %v", ids)
+	}
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "realtme"})); len(ids) != 0 {
+		t.Errorf("Expected no match without typo tolerance, got %v", ids)
+	}
+}
+
+func TestSearchIndexHighlights(t *testing.T) {
+	idx := articleIndex()
+
+	result := idx.Search(cocobase.SearchRequest{
+		Query:     "websocket",
+		Highlight: &cocobase.HighlightOptions{PreTag: "[", PostTag: "]"},
+	})
+
+	if len(result.Hits) != 1 {
+		t.Fatalf("Expected 1 hit, got %d", len(result.Hits))
+	}
+	snippets := result.Hits[0].Highlights["body"]
+	if len(snippets) != 1 || !strings.Contains(snippets[0], "[WebSocket]") {
+		t.Errorf("Expected highlighted snippet, got %v", result.Hits[0].Highlights)
+	}
+}
+
+func TestSearchIndexFilterAndRemove(t *testing.T) {
+	idx := articleIndex()
+
+	result := idx.Search(cocobase.SearchRequest{
+		Query:  "cocobase",
+		Filter: cocobase.NewQuery().Where("id", "a2"),
+	})
+	if ids := hitIDs(result); len(ids) != 1 || ids[0] != "a2" {
+		t.Errorf("Expected filtered hit a2, got %v", ids)
+	}
+
+	idx.Remove("a2")
+	if ids := hitIDs(idx.Search(cocobase.SearchRequest{Query: "websocket"})); len(ids) != 0 {
+		t.Errorf("Expected removed document to be gone, got %v", ids)
+	}
+	if idx.Len() != 2 {
+		t.Errorf("Expected 2 indexed documents, got %d", idx.Len())
+	}
+}
+
+func TestSearchDocumentsServer(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/collections/articles/search" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"hits":  []map[string]interface{}{{"document": map[string]interface{}{"id": "a1"}, "score": 1.5}},
+			"total": 1,
+		})
+	}))
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	result, err := client.SearchDocuments(context.Background(), "articles", cocobase.SearchRequest{
+		Query:  "cocobase",
+		Fields: []string{"title"},
+		Mode:   cocobase.SearchPhrase,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Local || result.Total != 1 || result.Hits[0].Score != 1.5 {
+		t.Errorf("Unexpected server result: %+v", result)
+	}
+	if body["query"] != "cocobase" || body["mode"] != "phrase" {
+		t.Errorf("Unexpected request body: %v", body)
+	}
+}
+
+func TestSearchDocumentsLocalFallback(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	if _, err := client.SearchDocuments(context.Background(), "articles", cocobase.SearchRequest{Query: "cocobase"}); err == nil {
+		t.Fatalf("Expected error without a local index")
+	}
+
+	client.UseLocalSearchIndex("articles", articleIndex())
+	result, err := client.SearchDocuments(context.Background(), "articles", cocobase.SearchRequest{Query: "operators"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Local || len(result.Hits) != 1 || result.Hits[0].Document.ID != "a3" {
+		t.Errorf("Expected local hit a3, got %+v", result)
+	}
+}
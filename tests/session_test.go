@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+// userServer answers /auth-collections/user with the user named by the
+// bearer token and records the token of every document request
+func userServer(t *testing.T) (string, chan string) {
+	t.Helper()
+	tokens := make(chan string, 100)
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get(cocobase.HeaderAuthorization), "Bearer ")
+		switch r.URL.Path {
+		case "/auth-collections/login":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(map[string]string{"access_token": body["email"] + "-token"})
+		case "/auth-collections/user":
+			if token == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.AppUser{ID: strings.TrimSuffix(token, "-token")})
+		default:
+			tokens <- token
+			json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+		}
+	})
+	return server.URL, tokens
+}
+
+func TestWithUserTokenPrecedence(t *testing.T) {
+	baseURL, tokens := userServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: baseURL})
+	client.SetToken("client-token")
+
+	ctx := cocobase.WithUserToken(context.Background(), "ctx-token")
+
+	client.GetDocument(ctx, "users", "doc1")
+	if got := <-tokens; got != "ctx-token" {
+		t.Errorf("Expected context token, got %q", got)
+	}
+
+	client.GetDocument(ctx, "users", "doc1", cocobase.WithBearerToken("call-token"))
+	if got := <-tokens; got != "call-token" {
+		t.Errorf("Expected call option to win over context, got %q", got)
+	}
+
+	client.GetDocument(context.Background(), "users", "doc1")
+	if got := <-tokens; got != "client-token" {
+		t.Errorf("Expected client token without context token, got %q", got)
+	}
+}
+
+func TestSessionsShareOneClient(t *testing.T) {
+	baseURL, tokens := userServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: baseURL})
+	ctx := context.Background()
+
+	alice, err := client.LoginSession(ctx, "alice", "password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bob, err := client.LoginSession(ctx, "bob", "password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if alice.User.ID != "alice" || bob.User.ID != "bob" {
+		t.Errorf("Expected session users alice and bob, got %s and %s", alice.User.ID, bob.User.ID)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected LoginSession to leave the client signed out")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			alice.GetDocument(ctx, "notes", "doc1")
+		}()
+		go func() {
+			defer wg.Done()
+			bob.GetDocument(ctx, "notes", "doc1")
+		}()
+	}
+	wg.Wait()
+	close(tokens)
+
+	counts := make(map[string]int)
+	for token := range tokens {
+		counts[token]++
+	}
+	if counts["alice-token"] != 10 || counts["bob-token"] != 10 {
+		t.Errorf("Expected 10 requests per session, got %v", counts)
+	}
+}
+
+func TestResumeSession(t *testing.T) {
+	baseURL, _ := userServer(t)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: baseURL})
+
+	session, err := client.ResumeSession(context.Background(), "carol-token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.User.ID != "carol" {
+		t.Errorf("Expected carol, got %s", session.User.ID)
+	}
+}
+
+func TestSessionStreamsDocuments(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get(cocobase.HeaderAuthorization), "Bearer ")
+		json.NewEncoder(w).Encode([]cocobase.Document{{ID: token + "-1"}, {ID: token + "-2"}})
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	client.SetToken("client-token")
+	session := client.NewSession("alice-token", nil)
+	ctx := context.Background()
+
+	it, err := session.IterateDocuments(ctx, "notes", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer it.Close()
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Document().ID)
+	}
+	if it.Err() != nil || len(ids) != 2 || ids[0] != "alice-token-1" {
+		t.Errorf("Expected the session's documents, got %v, %v", ids, it.Err())
+	}
+
+	ids = nil
+	err = session.StreamDocuments(ctx, "notes", nil, func(doc cocobase.Document) error {
+		ids = append(ids, doc.ID)
+		return nil
+	})
+	if err != nil || len(ids) != 2 || ids[1] != "alice-token-2" {
+		t.Errorf("Expected the session's documents, got %v, %v", ids, err)
+	}
+}
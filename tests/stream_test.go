@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func manyDocuments(n int) []cocobase.Document {
+	docs := make([]cocobase.Document, n)
+	for i := range docs {
+		docs[i] = cocobase.Document{ID: fmt.Sprintf("doc%d", i), Data: map[string]interface{}{"n": i}}
+	}
+	return docs
+}
+
+func TestStreamDocuments(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(manyDocuments(100))
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	var ids []string
+	err := client.StreamDocuments(context.Background(), "events", nil, func(doc cocobase.Document) error {
+		ids = append(ids, doc.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ids) != 100 || ids[0] != "doc0" || ids[99] != "doc99" {
+		t.Errorf("Expected doc0..doc99 in order, got %d documents", len(ids))
+	}
+}
+
+func TestStreamDocumentsStopsOnCallbackError(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(manyDocuments(10))
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	stop := errors.New("stop")
+	count := 0
+	err := client.StreamDocuments(context.Background(), "events", nil, func(doc cocobase.Document) error {
+		count++
+		if count == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected iteration to stop after 3 documents, got %d", count)
+	}
+}
+
+func TestIterateDocumentsRejectsNonArray(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"detail":"not a list"}`))
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	if _, err := client.IterateDocuments(context.Background(), "events", nil); err == nil {
+		t.Error("Expected an error for a non-array response")
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(manyDocuments(1000))
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, MaxResponseSize: 4096})
+
+	if _, err := client.ListDocuments(context.Background(), "events", nil); !errors.Is(err, cocobase.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+
+	err := client.StreamDocuments(context.Background(), "events", nil, func(cocobase.Document) error { return nil })
+	if !errors.Is(err, cocobase.ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge while streaming, got %v", err)
+	}
+}
+
+func TestGzipResponses(t *testing.T) {
+	var acceptEncoding string
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		json.NewEncoder(zw).Encode(manyDocuments(5))
+		zw.Close()
+	})
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+
+	docs, err := client.ListDocuments(context.Background(), "events", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(docs) != 5 {
+		t.Errorf("Expected 5 documents, got %d", len(docs))
+	}
+	if acceptEncoding != "gzip" {
+		t.Errorf("Expected Accept-Encoding: gzip, got %q", acceptEncoding)
+	}
+}
+
+func TestGzipRequests(t *testing.T) {
+	var encoding, name string
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		var payload struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		json.NewDecoder(body).Decode(&payload)
+		name, _ = payload.Data["name"].(string)
+		json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:              server.URL,
+		CompressRequests:     true,
+		CompressionThreshold: 100,
+	})
+	ctx := context.Background()
+
+	client.CreateDocument(ctx, "users", map[string]interface{}{"name": "Al"})
+	if encoding != "" {
+		t.Errorf("Expected small bodies to be sent uncompressed, got %q", encoding)
+	}
+
+	long := strings.Repeat("A", 500)
+	client.CreateDocument(ctx, "users", map[string]interface{}{"name": long})
+	if encoding != "gzip" {
+		t.Errorf("Expected large bodies to be gzipped, got %q", encoding)
+	}
+	if name != long {
+		t.Error("Expected the server to decode the compressed body")
+	}
+}
+
+func TestIterateDocumentsBypassesCache(t *testing.T) {
+	release := make(chan struct{})
+	var requests int
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[{"id":"doc1"},`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+		w.Write([]byte(`{"id":"doc2"}]`))
+	})
+
+	client := cocobase.NewClient(cocobase.Config{
+		BaseURL:       server.URL,
+		Cache:         &cocobase.CacheConfig{TTL: time.Minute},
+		CoalesceReads: true,
+	})
+	ctx := context.Background()
+
+	// The first document arrives while the server is still writing
+	start := time.Now()
+	it, err := client.IterateDocuments(ctx, "events", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !it.Next() || it.Document().ID != "doc1" {
+		t.Fatalf("Expected doc1, got %v", it.Err())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the response to be streamed, not buffered, took %s", elapsed)
+	}
+	close(release)
+	for it.Next() {
+	}
+	it.Close()
+
+	if err := client.StreamDocuments(ctx, "events", nil, func(cocobase.Document) error { return nil }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected streamed lists not to be cached, got %d requests", requests)
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+)
+
+func userTemplate() *cocobase.QueryTemplate {
+	query := cocobase.NewQuery().
+		Where("status", cocobase.Param("status", cocobase.ParamString)).
+		GreaterThanOrEqual("age", cocobase.Param("minAge", cocobase.ParamInteger)).
+		In("role", cocobase.Param("roles", cocobase.ParamList)).
+		Or().
+		Where("isPremium", cocobase.Param("premium", cocobase.ParamBool).WithDefault(true)).
+		Contains("name", ":name").
+		Done().
+		Limit(10)
+
+	return cocobase.NewQueryTemplate("active-adults", query, cocobase.Param("name", cocobase.ParamString).WithDefault(""))
+}
+
+func TestQueryTemplateBind(t *testing.T) {
+	tpl := userTemplate()
+
+	if len(tpl.Params) != 5 {
+		t.Fatalf("Expected 5 params, got %+v", tpl.Params)
+	}
+
+	query, err := tpl.Bind(map[string]interface{}{
+		"status": "active",
+		"minAge": 18,
+		"roles":  []string{"admin", "editor"},
+		"name":   "jo",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result := query.Build()
+	params := parseQuery(result)
+
+	if params.Get("status") != "active" || params.Get("age_gte") != "18" || params.Get("role_in") != "admin,editor" {
+		t.Errorf("Unexpected bound query: %s", result)
+	}
+	if params.Get("[or]isPremium") != "true" || params.Get("[or]name_contains") != "jo" {
+		t.Errorf("Expected OR placeholders bound, got %s", result)
+	}
+	if params.Get("limit") != "10" {
+		t.Errorf("Expected limit=10, got %s", result)
+	}
+}
+
+func TestQueryTemplateBindErrors(t *testing.T) {
+	tpl := userTemplate()
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"status": "active", "minAge": 18, "roles": []string{"admin"}}
+	}
+
+	params := valid()
+	delete(params, "status")
+	if _, err := tpl.Bind(params); err == nil {
+		t.Errorf("Expected error for missing parameter")
+	}
+
+	params = valid()
+	params["minAge"] = "eighteen"
+	if _, err := tpl.Bind(params); err == nil {
+		t.Errorf("Expected type error for minAge")
+	}
+
+	params = valid()
+	params["minAge"] = 18.5
+	if _, err := tpl.Bind(params); err == nil {
+		t.Errorf("Expected type error for non-integral minAge")
+	}
+
+	params = valid()
+	params["extra"] = 1
+	if _, err := tpl.Bind(params); err == nil {
+		t.Errorf("Expected error for unknown parameter")
+	}
+}
+
+func TestQueryTemplateTimeParam(t *testing.T) {
+	tpl := cocobase.NewQueryTemplate("since", cocobase.NewQuery().
+		GreaterThan("created_at", cocobase.Param("since", cocobase.ParamTime)))
+
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	query, err := tpl.Bind(map[string]interface{}{"since": since})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !hasParam(query.Build(), "created_at_gt", "2024-05-01T12:00:00Z") {
+		t.Errorf("Unexpected bound query: %s", query.Build())
+	}
+}
+
+func TestQueryTemplateJSONRoundTrip(t *testing.T) {
+	raw, err := json.Marshal(userTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var restored cocobase.QueryTemplate
+	if err := json.Unmarshal(raw, &restored); err != nil {
+		t.Fatal(err)
+	}
+
+	// Values decoded from JSON arrive as float64 and []interface{}
+	query, err := restored.Bind(map[string]interface{}{
+		"status": "active",
+		"minAge": float64(21),
+		"roles":  []interface{}{"admin"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	params := parseQuery(query.Build())
+	if params.Get("age_gte") != "21" || params.Get("role_in") != "admin" || params.Get("[or]name_contains") != "" {
+		t.Errorf("Unexpected restored query: %s", query.Build())
+	}
+}
+
+func TestLoadQueryTemplate(t *testing.T) {
+	data, _ := userTemplate().ToData()
+	var listed []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/collections/saved_queries/documents":
+			json.NewEncoder(w).Encode([]cocobase.Document{{ID: "q1", Data: data}})
+		case "/collections/users/documents":
+			listed = append(listed, r.URL.RawQuery)
+			json.NewEncoder(w).Encode([]cocobase.Document{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	tpl, err := client.LoadQueryTemplate(ctx, "saved_queries", "active-adults")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tpl.Name != "active-adults" {
+		t.Errorf("Unexpected template: %+v", tpl)
+	}
+
+	_, err = client.ListDocumentsWithTemplate(ctx, "users", tpl, map[string]interface{}{
+		"status": "active",
+		"minAge": 30,
+		"roles":  []string{"admin"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(listed) != 1 || !hasParam(listed[0], "age_gte", "30") {
+		t.Errorf("Expected bound list query, got %v", listed)
+	}
+}
@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lordace-coder/cocobase-go/cocobase"
+	"github.com/lordace-coder/cocobase-go/storage"
+)
+
+// jwt returns an unsigned JWT expiring at exp
+func jwt(id int, exp time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"user1","jti":"%d","exp":%d}`, id, exp.Unix())))
+	return header + "." + payload + "."
+}
+
+// refreshServer issues rotating access and refresh tokens and rejects
+// requests with anything but the latest access token
+type refreshServer struct {
+	mu        sync.Mutex
+	lifetime  time.Duration
+	issued    int
+	access    string
+	refresh   string
+	refreshes int
+	rejectAll bool
+	URL       string
+}
+
+func newRefreshServer(t *testing.T, lifetime time.Duration) *refreshServer {
+	t.Helper()
+	s := &refreshServer{lifetime: lifetime}
+	server := documentServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/auth-collections/login":
+			json.NewEncoder(w).Encode(s.issue())
+		case "/auth-collections/refresh":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if s.rejectAll || body["refresh_token"] != s.refresh {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Slow enough for concurrent callers to pile up
+			time.Sleep(20 * time.Millisecond)
+			s.refreshes++
+			json.NewEncoder(w).Encode(s.issue())
+		default:
+			if r.Header.Get(cocobase.HeaderAuthorization) != "Bearer "+s.access {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Path == "/auth-collections/user" {
+				json.NewEncoder(w).Encode(cocobase.AppUser{ID: "user1"})
+				return
+			}
+			json.NewEncoder(w).Encode(cocobase.Document{ID: "doc1"})
+		}
+	})
+	s.URL = server.URL
+	return s
+}
+
+func (s *refreshServer) issue() cocobase.TokenResponse {
+	s.issued++
+	s.access = jwt(s.issued, time.Now().Add(s.lifetime))
+	s.refresh = fmt.Sprintf("refresh-%d", s.issued)
+	return cocobase.TokenResponse{AccessToken: s.access, RefreshToken: s.refresh}
+}
+
+// revoke invalidates the current access token, as if it had expired
+func (s *refreshServer) revoke() {
+	s.mu.Lock()
+	s.access = "revoked"
+	s.mu.Unlock()
+}
+
+func (s *refreshServer) refreshCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshes
+}
+
+func TestRefreshAndRetryOnUnauthorized(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if exp := client.TokenExpiry(); time.Until(exp) < 59*time.Minute {
+		t.Errorf("Expected expiry from the JWT exp claim, got %v", exp)
+	}
+
+	server.revoke()
+	if _, err := client.GetDocument(ctx, "users", "doc1"); err != nil {
+		t.Fatalf("Expected the call to succeed after refreshing, got %v", err)
+	}
+	if n := server.refreshCount(); n != 1 {
+		t.Errorf("Expected 1 refresh, got %d", n)
+	}
+}
+
+func TestProactiveRefresh(t *testing.T) {
+	server := newRefreshServer(t, 10*time.Second)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before := client.GetToken()
+
+	// The token expires within the default leeway, so it is refreshed first
+	if _, err := client.GetDocument(ctx, "users", "doc1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client.GetToken() == before {
+		t.Error("Expected the token to be refreshed before the call")
+	}
+	if n := server.refreshCount(); n != 1 {
+		t.Errorf("Expected 1 refresh, got %d", n)
+	}
+}
+
+func TestConcurrentRefreshesAreSerialized(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.revoke()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetDocument(ctx, "users", "doc1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if n := server.refreshCount(); n != 1 {
+		t.Errorf("Expected a single refresh for concurrent calls, got %d", n)
+	}
+}
+
+func TestRefreshedTokensArePersisted(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	store := storage.NewMemoryStorage()
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.RefreshSession(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if token, _ := store.Get("cocobase-token"); token != client.GetToken() {
+		t.Errorf("Expected refreshed access token in storage, got %q", token)
+	}
+	if refresh, _ := store.Get("cocobase-refresh-token"); refresh != "refresh-2" {
+		t.Errorf("Expected rotated refresh token in storage, got %q", refresh)
+	}
+
+	// A new process restores the session and refreshes the stale token
+	server.revoke()
+	restored := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	if err := restored.InitAuth(ctx); err != nil {
+		t.Fatalf("Expected the stored session to be restored, got %v", err)
+	}
+	if refresh, _ := store.Get("cocobase-refresh-token"); refresh != "refresh-3" {
+		t.Errorf("Expected refresh on restore, got %q", refresh)
+	}
+
+	if err := restored.Logout(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := store.Get("cocobase-refresh-token"); err == nil {
+		t.Error("Expected Logout to delete the refresh token")
+	}
+}
+
+func TestRejectedRefreshReturnsUnauthorized(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.mu.Lock()
+	server.rejectAll = true
+	server.mu.Unlock()
+	server.revoke()
+
+	_, err := client.GetDocument(ctx, "users", "doc1")
+	var apiErr *cocobase.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected the original 401, got %v", err)
+	}
+
+	if err := client.RefreshSession(ctx); !errors.Is(err, cocobase.ErrNoRefreshToken) {
+		t.Errorf("Expected the rejected refresh token to be dropped, got %v", err)
+	}
+}
+
+func TestTokenRefreshCanBeDisabled(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, DisableTokenRefresh: true})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.revoke()
+
+	if _, err := client.GetDocument(ctx, "users", "doc1"); err == nil {
+		t.Error("Expected 401 with refresh disabled")
+	}
+	if n := server.refreshCount(); n != 0 {
+		t.Errorf("Expected no refresh, got %d", n)
+	}
+}
+
+func TestSetTokenDropsRefreshToken(t *testing.T) {
+	server := newRefreshServer(t, time.Hour)
+	store := storage.NewMemoryStorage()
+	client := cocobase.NewClient(cocobase.Config{BaseURL: server.URL, Storage: store})
+	ctx := context.Background()
+
+	if err := client.Login(ctx, "alice@example.com", "password"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.SetToken(client.GetToken())
+	if refresh, _ := store.Get("cocobase-refresh-token"); refresh != "refresh-1" {
+		t.Errorf("Expected the same token to keep the refresh token, got %q", refresh)
+	}
+
+	client.SetToken("bob-token")
+	if _, err := store.Get("cocobase-refresh-token"); err == nil {
+		t.Error("Expected a new token to delete the stored refresh token")
+	}
+	if err := client.RefreshSession(ctx); !errors.Is(err, cocobase.ErrNoRefreshToken) {
+		t.Errorf("Expected no refresh token, got %v", err)
+	}
+
+	client.SetTokens("carol-token", "carol-refresh")
+	if refresh, _ := store.Get("cocobase-refresh-token"); refresh != "carol-refresh" {
+		t.Errorf("Expected SetTokens to store the refresh token, got %q", refresh)
+	}
+}